@@ -0,0 +1,347 @@
+package flagParser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Config files are read into flat key/value maps. Nested JSON
+// objects & TOML tables give dotted keys ('todo.due'); values are
+// kept as strings for the usual flag handling. Only the subset of
+// TOML a settings file needs is read: tables, bare, quoted & dotted
+// keys, strings, numbers, booleans & dates. Arrays & inline tables
+// are rejected; use a comma-separated string instead. Keys no flag
+// uses are ignored.
+
+// Flag values from one file
+type Config struct {
+	Path   string
+	Values map[string]ConfigValue
+}
+
+type ConfigValue struct {
+	Value string
+	Line  int
+}
+
+var (
+	tomlBareKeyPattern  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	tomlIntegerPattern  = regexp.MustCompile(`^[+-]?\d[\d_]*$`)
+	tomlFloatPattern    = regexp.MustCompile(`^[+-]?\d[\d_]*(?:\.\d[\d_]*)?(?:[eE][+-]?\d+)?$`)
+	tomlDateTimePattern = regexp.MustCompile(`^(?:\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:\d{2})?)?|\d{2}:\d{2}(?::\d{2})?)$`)
+)
+
+// Reads a JSON object. The line of each key is kept for errors &
+// ValueSource; null values count as unset.
+func ParseJSONConfig(r io.Reader) (Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Config{}, &ConfigFileError{Reason: err.Error()}
+	}
+	lineAt := func(offset int64) int {
+		return 1 + bytes.Count(data[:offset], []byte("\n"))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	cfg := Config{Values: make(map[string]ConfigValue)}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return Config{}, jsonConfigError(err, lineAt)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return Config{}, &ConfigFileError{Line: lineAt(dec.InputOffset()), Reason: "expected an object"}
+	}
+	if err := readJSONMembers(dec, "", cfg.Values, lineAt); err != nil {
+		return Config{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return Config{}, &ConfigFileError{Line: lineAt(dec.InputOffset()), Reason: "unexpected data after the object"}
+	}
+	return cfg, nil
+}
+
+// Reads members up to & including the closing '}'
+func readJSONMembers(dec *json.Decoder, prefix string, vals map[string]ConfigValue, lineAt func(int64) int) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return jsonConfigError(err, lineAt)
+		}
+		key, line := prefix+tok.(string), lineAt(dec.InputOffset())
+		fail := func(reason string) error {
+			return &ConfigFileError{Line: line, Reason: reason}
+		}
+		if _, dup := vals[key]; dup {
+			return fail(fmt.Sprintf("duplicate key '%v'", key))
+		}
+
+		tok, err = dec.Token()
+		if err != nil {
+			return jsonConfigError(err, lineAt)
+		}
+		switch v := tok.(type) {
+		case json.Delim:
+			if v != '{' {
+				return fail(fmt.Sprintf("arrays aren't supported for '%v'; use a comma-separated string", key))
+			}
+			if err := readJSONMembers(dec, key+".", vals, lineAt); err != nil {
+				return err
+			}
+		case string:
+			vals[key] = ConfigValue{Value: v, Line: line}
+		case json.Number:
+			vals[key] = ConfigValue{Value: v.String(), Line: line}
+		case bool:
+			vals[key] = ConfigValue{Value: strconv.FormatBool(v), Line: line}
+		}
+	}
+
+	if _, err := dec.Token(); err != nil { //closing '}'
+		return jsonConfigError(err, lineAt)
+	}
+	return nil
+}
+
+func jsonConfigError(err error, lineAt func(int64) int) error {
+	if se, ok := err.(*json.SyntaxError); ok {
+		return &ConfigFileError{Line: lineAt(se.Offset), Reason: se.Error()}
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return &ConfigFileError{Reason: "unexpected end of file"}
+	}
+	return &ConfigFileError{Reason: err.Error()}
+}
+
+// Reads the TOML subset described at the top of the file
+func ParseTOMLConfig(r io.Reader) (Config, error) {
+	cfg := Config{Values: make(map[string]ConfigValue)}
+	tables := make(map[string]bool) //headers & dotted key prefixes
+	table := ""
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		fail := func(reason string) (Config, error) {
+			return Config{}, &ConfigFileError{Line: line, Reason: reason}
+		}
+		text := sc.Text()
+		if i := indexOutsideTOMLStrings(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "[["):
+			return fail("arrays of tables aren't supported")
+		case strings.HasPrefix(text, "["):
+			if !strings.HasSuffix(text, "]") {
+				return fail("unclosed table header")
+			}
+			key, err := parseTOMLKey(text[1 : len(text)-1])
+			if err != nil {
+				return fail(err.Error())
+			}
+			if reason := addTOMLTable(cfg, tables, key); reason != "" {
+				return fail(reason)
+			}
+			table = key + "."
+			continue
+		}
+
+		eq := indexOutsideTOMLStrings(text, '=')
+		if eq < 0 {
+			return fail("expected 'key = value'")
+		}
+		key, err := parseTOMLKey(text[:eq])
+		if err != nil {
+			return fail(err.Error())
+		}
+		key = table + key
+		val, err := parseTOMLValue(strings.TrimSpace(text[eq+1:]))
+		if err != nil {
+			return fail(fmt.Sprintf("'%v': %v", key, err))
+		}
+		if _, dup := cfg.Values[key]; dup {
+			return fail(fmt.Sprintf("duplicate key '%v'", key))
+		}
+		if tables[key] {
+			return fail(fmt.Sprintf("'%v' is already a table", key))
+		}
+		if i := strings.LastIndexByte(key, '.'); i >= 0 {
+			if reason := addTOMLTable(cfg, tables, key[:i]); reason != "" {
+				return fail(reason)
+			}
+		}
+		cfg.Values[key] = ConfigValue{Value: val, Line: line}
+	}
+	if err := sc.Err(); err != nil {
+		return Config{}, &ConfigFileError{Reason: err.Error()}
+	}
+	return cfg, nil
+}
+
+// Marks key & its parents as tables. Gives the reason if one of
+// them is already a value.
+func addTOMLTable(cfg Config, tables map[string]bool, key string) string {
+	for i := 0; i <= len(key); i++ {
+		if i < len(key) && key[i] != '.' {
+			continue
+		}
+		if _, isValue := cfg.Values[key[:i]]; isValue {
+			return fmt.Sprintf("'%v' is already a value", key[:i])
+		}
+		tables[key[:i]] = true
+	}
+	return ""
+}
+
+// Index of the first c outside quoted strings, or -1
+func indexOutsideTOMLStrings(s string, c byte) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++ //skip the escaped char
+		case quote != 0 && s[i] == quote:
+			quote = 0
+		case quote != 0:
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == c:
+			return i
+		}
+	}
+	return -1
+}
+
+// 'todo . "due date"' -> 'todo.due date'
+func parseTOMLKey(s string) (string, error) {
+	var parts []string
+	for s = strings.TrimSpace(s); ; {
+		end := indexOutsideTOMLStrings(s, '.')
+		if end < 0 {
+			end = len(s)
+		}
+
+		part := strings.TrimSpace(s[:end])
+		switch {
+		case tomlBareKeyPattern.MatchString(part):
+		case len(part) >= 2 && part[0] == '\'' && part[len(part)-1] == '\'':
+			part = part[1 : len(part)-1]
+		case strings.HasPrefix(part, `"`):
+			uq, err := strconv.Unquote(part)
+			if err != nil {
+				return "", fmt.Errorf("invalid key '%v'", part)
+			}
+			part = uq
+		default:
+			return "", fmt.Errorf("invalid key '%v'", part)
+		}
+		if part == "" {
+			return "", fmt.Errorf("empty key")
+		}
+		parts = append(parts, part)
+
+		if end == len(s) {
+			return strings.Join(parts, "."), nil
+		}
+		s = s[end+1:]
+	}
+}
+
+func parseTOMLValue(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''"):
+		return "", fmt.Errorf("multi-line strings aren't supported")
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %v", s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") || strings.Contains(s[1:len(s)-1], "'") {
+			return "", fmt.Errorf("invalid string %v", s)
+		}
+		return s[1 : len(s)-1], nil
+	case strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{"):
+		return "", fmt.Errorf("arrays & inline tables aren't supported; use a comma-separated string")
+	case s == "true" || s == "false" || tomlDateTimePattern.MatchString(s):
+		return s, nil
+	case tomlIntegerPattern.MatchString(s) || tomlFloatPattern.MatchString(s):
+		return strings.ReplaceAll(s, "_", ""), nil
+	case s == "":
+		return "", fmt.Errorf("missing value")
+	}
+	return "", fmt.Errorf("unquoted value '%v'; strings need quotes", s)
+}
+
+// Opens path & reads it with ParseJSONConfig
+func LoadJSONConfig(path string) (Config, error) {
+	return loadConfig(path, ParseJSONConfig)
+}
+
+// Opens path & reads it with ParseTOMLConfig
+func LoadTOMLConfig(path string) (Config, error) {
+	return loadConfig(path, ParseTOMLConfig)
+}
+
+// Picks the reader by extension, '.json' or '.toml'
+func LoadConfig(path string) (Config, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return LoadJSONConfig(path)
+	case ".toml":
+		return LoadTOMLConfig(path)
+	}
+	return Config{}, &ConfigFileError{Path: path, Reason: "expected a .json or .toml file"}
+}
+
+func loadConfig(path string, parse func(io.Reader) (Config, error)) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, &ConfigFileError{Path: path, Reason: err.Error()}
+	}
+	defer f.Close()
+
+	cfg, err := parse(f)
+	if cfe, ok := err.(*ConfigFileError); ok {
+		cfe.Path = path
+	}
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Path = path
+	return cfg, nil
+}
+
+// Finds '<app>.toml' or '<app>.json' in the user's config directory
+// (e.g. ~/.config on Linux)
+func UserConfigFile(app string) (string, bool) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false
+	}
+	return findConfigFile(dir, app)
+}
+
+func findConfigFile(dir, app string) (string, bool) {
+	for _, ext := range []string{".toml", ".json"} {
+		path := filepath.Join(dir, app+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
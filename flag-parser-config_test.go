@@ -0,0 +1,156 @@
+package flagParser
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const _testTOML = `# todo defaults
+tag = "work" # inline comment
+max = 1_000
+"due date" = '+1w'
+
+[todo]
+urgent = true
+due = 2022-03-20
+note = "say \"hi\" # not a comment"
+`
+
+const _testJSON = `{
+  "tag": "work",
+  "max": 12,
+  "skip": null,
+  "todo": {
+    "urgent": true,
+    "due": "+1w"
+  }
+}`
+
+func TestTOMLConfig(t *testing.T) {
+	cfg, err := ParseTOMLConfig(strings.NewReader(_testTOML))
+	if err != nil {
+		t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+		return
+	}
+
+	exp := map[string]ConfigValue{
+		"tag":         {Value: "work", Line: 2},
+		"max":         {Value: "1000", Line: 3},
+		"due date":    {Value: "+1w", Line: 4},
+		"todo.urgent": {Value: "true", Line: 7},
+		"todo.due":    {Value: "2022-03-20", Line: 8},
+		"todo.note":   {Value: `say "hi" # not a comment`, Line: 9},
+	}
+	if len(cfg.Values) != len(exp) {
+		t.Errorf(">>>>FAILED: wrong keys. \nExp\t'%v' \nGot\t'%v'", exp, cfg.Values)
+	}
+	for k, v := range exp {
+		if cfg.Values[k] != v {
+			t.Errorf(">>>>FAILED: key '%v'. \nExp\t'%v' \nGot\t'%v'", k, v, cfg.Values[k])
+		}
+	}
+}
+
+func TestJSONConfig(t *testing.T) {
+	cfg, err := ParseJSONConfig(strings.NewReader(_testJSON))
+	if err != nil {
+		t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+		return
+	}
+
+	exp := map[string]ConfigValue{
+		"tag":         {Value: "work", Line: 2},
+		"max":         {Value: "12", Line: 3},
+		"todo.urgent": {Value: "true", Line: 6},
+		"todo.due":    {Value: "+1w", Line: 7},
+	}
+	if len(cfg.Values) != len(exp) {
+		t.Errorf(">>>>FAILED: wrong keys. \nExp\t'%v' \nGot\t'%v'", exp, cfg.Values)
+	}
+	for k, v := range exp {
+		if cfg.Values[k] != v {
+			t.Errorf(">>>>FAILED: key '%v'. \nExp\t'%v' \nGot\t'%v'", k, v, cfg.Values[k])
+		}
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	toml := map[string]int{
+		"a = 1\nb = [1, 2]\n":             2,
+		"a = 1\na = 2\n":                  2,
+		"[t]\nb = hello\n":                2,
+		"a = \"unclosed\n":                1,
+		"a = 1\n[[items]]\n":              2,
+		"just a line\n":                   1,
+		"a = '''\nmulti'''\n":             1,
+		"bad key! = 1\n":                  1,
+		"[table\nb = 1\n":                 1,
+		"a = \"x\" trailing\n":            1,
+		"a = \"ok\"\nb = \n":              2,
+		"a = 1\n\n\nb = true2\n":          4,
+		"a = 1\n[a]\nb = 2\n":             2,
+		"a = 1\na.b = 2\n":                2,
+		"[t]\nx.y = 1\nx = 2\n":           3,
+		"a.b = 1\na = 2\n":                2,
+		"[a.b]\nc = 1\n[x]\n[a]\nb = 2\n": 5,
+		"'' = 1\n":                        1,
+		"a = 1\n[\"\"]\n":                 2,
+	}
+	for content, line := range toml {
+		_, err := ParseTOMLConfig(strings.NewReader(content))
+		cfe, ok := err.(*ConfigFileError)
+		if !ok || cfe.Line != line {
+			t.Errorf(">>>>FAILED: toml '%v'. \nExp\tline %v \nGot\t'%v'", content, line, err)
+		}
+	}
+
+	json := map[string]int{
+		"{\n\"a\": [1]\n}":        2,
+		"{\n\"a\": 1,\n\"a\": 2}": 3,
+		"{\n\"a\": 1,\n}":         2, //reported at the trailing comma
+		"[1]":                     1,
+		"{\"a\": 1} {}":           1,
+	}
+	for content, line := range json {
+		_, err := ParseJSONConfig(strings.NewReader(content))
+		cfe, ok := err.(*ConfigFileError)
+		if !ok || cfe.Line != line {
+			t.Errorf(">>>>FAILED: json '%v'. \nExp\tline %v \nGot\t'%v'", content, line, err)
+		}
+	}
+	if _, err := ParseJSONConfig(strings.NewReader("{\"a\": ")); !_errorsMatch(err, &ConfigFileError{}) {
+		t.Errorf(">>>>FAILED: truncated json. \nGot\t'%v'", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	tomlPath := _writeTestFile(t, "todo.toml", _testTOML)
+	jsonPath := _writeTestFile(t, "todo.json", _testJSON)
+
+	for _, path := range []string{tomlPath, jsonPath} {
+		cfg, err := LoadConfig(path)
+		if err != nil || cfg.Path != path || cfg.Values["tag"].Value != "work" {
+			t.Errorf(">>>>FAILED: load '%v'. \nGot\t'%v' '%v'", path, cfg, err)
+		}
+	}
+
+	bad := _writeTestFile(t, "bad.toml", "a = 1\nb = nope\n")
+	_, err := LoadConfig(bad)
+	if cfe, ok := err.(*ConfigFileError); !ok || cfe.Path != bad || cfe.Line != 2 {
+		t.Errorf(">>>>FAILED: expected path & line. \nGot\t'%v'", err)
+	}
+	for _, path := range []string{filepath.Join(dir, "todo.yaml"), filepath.Join(dir, "missing.toml")} {
+		if _, err := LoadConfig(path); !_errorsMatch(err, &ConfigFileError{}) {
+			t.Errorf(">>>>FAILED: '%v'. \nGot\t'%v'", path, err)
+		}
+	}
+
+	if path, ok := findConfigFile(filepath.Dir(jsonPath), "todo"); !ok || path != jsonPath {
+		t.Errorf(">>>>FAILED: find config. \nGot\t'%v'", path)
+	}
+	if _, ok := findConfigFile(dir, "todo"); ok {
+		t.Errorf(">>>>FAILED: found config in empty dir")
+	}
+}
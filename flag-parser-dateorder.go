@@ -0,0 +1,109 @@
+package flagParser
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field order of all-numeric literal dates ('14/03/2022')
+type DateOrder string
+
+const (
+	YMD DateOrder = "YMD"
+	DMY DateOrder = "DMY"
+	MDY DateOrder = "MDY"
+)
+
+// Three numbers with the same '/', '.' or '-' between them
+var numericDatePattern = regexp.MustCompile(`^(\d{1,4})([/.\-])(\d{1,4})([/.\-])(\d{1,4})$`)
+
+// Parser orders take precedence over the locale's
+func (fp *FlagParser) dateOrders() []DateOrder {
+	if len(fp.DateOrders) > 0 {
+		return fp.DateOrders
+	}
+	if fp.Locale != nil {
+		return fp.Locale.DateOrders
+	}
+	return nil
+}
+
+// Reads a numeric literal in each of the expected orders. Years must
+// have 4 digits. If the orders give different valid dates, as DMY
+// & MDY do for '03/04/2022', the input is ambiguous & rejected
+// rather than guessed at.
+func (fp *FlagParser) parseOrderedDate(input string, fi flag_info_key) (time.Time, bool, error) {
+	orders := fp.dateOrders()
+	m := numericDatePattern.FindStringSubmatch(strings.TrimSpace(input))
+	if len(orders) == 0 || m == nil || m[2] != m[4] {
+		return time.Time{}, false, nil
+	}
+	fields := []string{m[1], m[3], m[5]}
+
+	var found []time.Time
+	var foundOrders []DateOrder
+	var calErr error
+	for _, o := range orders {
+		d, ok, err := dateFromFields(fields, o, fp.locationFor(fi))
+		if err != nil && calErr == nil {
+			calErr = &InvalidCalendarDateError{Input: strings.TrimSpace(input), Component: err.Error()}
+		}
+		if !ok || containsDate(found, d) {
+			continue
+		}
+		found = append(found, d)
+		foundOrders = append(foundOrders, o)
+	}
+
+	switch len(found) {
+	case 0:
+		return time.Time{}, false, calErr //nil if no order had the right shape
+	case 1:
+		return found[0], true, nil
+	}
+
+	var dates []string
+	for _, d := range found {
+		dates = append(dates, fp.FormatDate(d))
+	}
+	return time.Time{}, false, &AmbiguousDateError{Input: strings.TrimSpace(input), Orders: foundOrders, Dates: dates}
+}
+
+// Returns false without an error if the fields don't have the
+// order's shape; the error names the component out of range.
+func dateFromFields(fields []string, order DateOrder, loc *time.Location) (time.Time, bool, error) {
+	idx := strings.Index(string(order), "Y")
+	if len(order) != 3 || idx < 0 || len(fields[idx]) != 4 {
+		return time.Time{}, false, nil
+	}
+
+	vals := make(map[byte]int)
+	for i := range fields {
+		if i != idx && len(fields[i]) > 2 {
+			return time.Time{}, false, nil
+		}
+		vals[order[i]], _ = strconv.Atoi(fields[i])
+	}
+
+	y, mo, d := vals['Y'], vals['M'], vals['D']
+	if mo < 1 || mo > 12 {
+		return time.Time{}, false, errors.New("month")
+	}
+	t := time.Date(y, time.Month(mo), d, 0, 0, 0, 0, loc)
+	if d < 1 || t.Day() != d { //e.g. 31/04/2022
+		return time.Time{}, false, errors.New("day")
+	}
+	return t, true, nil
+}
+
+func containsDate(ds []time.Time, d time.Time) bool {
+	for _, v := range ds {
+		if v.Equal(d) {
+			return true
+		}
+	}
+	return false
+}
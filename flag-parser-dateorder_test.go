@@ -0,0 +1,132 @@
+package flagParser
+
+import (
+	"testing"
+)
+
+func _withDateOrders(orders ...DateOrder) func(*FlagParser) {
+	return func(fp *FlagParser) {
+		fp.DateOrders = orders
+	}
+}
+
+func _getDateOrderTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "14/03/2022"},
+		expected:    []string{"-d", "2022-03-14"},
+		name:        "day first",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY),
+	}, {
+		args:        []string{"-d", "03/14/2022"},
+		expected:    []string{"-d", "2022-03-14"},
+		name:        "month first",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(MDY),
+	}, {
+		args:        []string{"-d", "2022.3.1"},
+		expected:    []string{"-d", "2022-03-01"},
+		name:        "year first with dots",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(YMD),
+	}, {
+		args:        []string{"-d", "03/04/2022"},
+		expected:    []string{"-d", "2022-04-03"},
+		name:        "single order isn't ambiguous",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY),
+	}, {
+		args:        []string{"-d", "14/03/2022"},
+		expected:    []string{"-d", "2022-03-14"},
+		name:        "only one order fits",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY, MDY),
+	}, {
+		args:        []string{"-d", "05/05/2022"},
+		expected:    []string{"-d", "2022-05-05"},
+		name:        "orders agree",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY, MDY),
+	}, {
+		args:        []string{"-d", "03/04/2022"},
+		expected:    []string{},
+		name:        "ambiguous",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY, MDY),
+		err:         &AmbiguousDateError{},
+	}, {
+		args:        []string{"-d", "01/03/2022:15/03/2022"},
+		expected:    []string{"-d", "2022-03-01:2022-03-15"},
+		name:        "range",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY),
+	}, {
+		args:        []string{"-d", "14/03/2022"},
+		expected:    []string{"-d", "14.03.2022"},
+		name:        "normalised to output layout",
+		systemFlags: _getRangeTestFlags,
+		configure: func(fp *FlagParser) {
+			fp.DateOrders = []DateOrder{DMY}
+			fp.DateOutputLayout = "02.01.2006"
+		},
+	}, {
+		args:        []string{"-d", "2022-03-20"},
+		expected:    []string{"-d", "2022-03-20"},
+		name:        "iso layout still accepted",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY),
+	}, {
+		args:        []string{"-d", "1/4/2022"},
+		expected:    []string{"-d", "2022-04-01"},
+		name:        "locale order",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withLocale(German()),
+	}, {
+		args:        []string{"-d", "31/04/2022"},
+		expected:    []string{},
+		name:        "no valid order",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY, MDY),
+		err:         &InvalidCalendarDateError{},
+	}, {
+		args:        []string{"-d", "14/03-2022"},
+		expected:    []string{},
+		name:        "mixed separators",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY),
+		err:         &InvalidDateLiteralError{},
+	}, {
+		args:        []string{"-d", "14/03/22"},
+		expected:    []string{},
+		name:        "two digit year",
+		systemFlags: _getRangeTestFlags,
+		configure:   _withDateOrders(DMY),
+		err:         &InvalidDateLiteralError{},
+	}, {
+		args:        []string{"-d", "14/03/2022"},
+		expected:    []string{},
+		name:        "no orders configured",
+		systemFlags: _getRangeTestFlags,
+		err:         &InvalidDateLiteralError{},
+	}}
+}
+
+func TestDateOrders(t *testing.T) {
+	tcs := _getDateOrderTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestAmbiguousDateDetail(t *testing.T) {
+	fp := NewFlagParser(_getRangeTestFlags(), []string{"-d", "03/04/2022"}, WithNowAs(returnNowString(), "2006-01-02"))
+	fp.DateOrders = []DateOrder{DMY, MDY}
+	_, err := fp.ParseUserInput()
+
+	amb, ok := err.(*AmbiguousDateError)
+	if !ok || len(amb.Dates) != 2 || amb.Dates[0] != "2022-04-03" || amb.Orders[1] != MDY {
+		t.Errorf(">>>>FAILED: ambiguity detail. \nGot\t'%v'", err)
+	}
+}
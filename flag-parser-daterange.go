@@ -0,0 +1,189 @@
+package flagParser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Typed form of a resolved date range. Date-only ranges include the
+// whole of their End day; timestamp ranges end at End itself. A single
+// date in a date list is a range with Single set & Start == End.
+type DateRange struct {
+	Start     time.Time
+	End       time.Time
+	OpenStart bool
+	OpenEnd   bool
+	HasTime   bool
+	Single    bool
+	Layout    string //used by String()
+	Separator string //used by String()
+}
+
+// Parses the string form produced by ParseUserInput. An empty side is
+// open. Layouts with minutes ('04') give timestamp ranges.
+func ParseDateRange(s, sep, layout string) (DateRange, error) {
+	dr := DateRange{Layout: layout, Separator: sep, HasTime: strings.Contains(layout, "04")}
+
+	isRng, sides, err := splitOnSeparator(s, sep)
+	if err != nil {
+		return DateRange{}, &MalformedDateRangeError{Err: err}
+	}
+	if !isRng || len(sides) != 2 || (sides[0] == "" && sides[1] == "") {
+		return DateRange{}, &MalformedDateRangeError{}
+	}
+
+	if dr.OpenStart = sides[0] == ""; !dr.OpenStart {
+		dr.Start, err = parseLayout(layout, sides[0], time.UTC)
+		if err != nil {
+			return DateRange{}, &MalformedDateRangeError{Err: &InvalidDateLiteralError{Input: sides[0], Layouts: []string{layout}}}
+		}
+	}
+	if dr.OpenEnd = sides[1] == ""; !dr.OpenEnd {
+		dr.End, err = parseLayout(layout, sides[1], time.UTC)
+		if err != nil {
+			return DateRange{}, &MalformedDateRangeError{Err: &InvalidDateLiteralError{Input: sides[1], Layouts: []string{layout}}}
+		}
+	}
+	return dr, nil
+}
+
+// Serialises back to 'start:end', leaving open sides empty
+func (dr DateRange) String() string {
+	if dr.Single {
+		return formatLayout(dr.Start, dr.Layout)
+	}
+
+	var start, end string
+	if !dr.OpenStart {
+		start = formatLayout(dr.Start, dr.Layout)
+	}
+	if !dr.OpenEnd {
+		end = formatLayout(dr.End, dr.Layout)
+	}
+	return start + dr.Separator + end
+}
+
+func (dr DateRange) IsOpen() bool {
+	return dr.OpenStart || dr.OpenEnd
+}
+
+// First moment after the range; the day after End for date ranges
+func (dr DateRange) exclusiveEnd() time.Time {
+	if dr.HasTime {
+		return dr.End
+	}
+	return dr.End.AddDate(0, 0, 1)
+}
+
+// Reports whether t falls within the range. A reversed
+// range contains nothing.
+func (dr DateRange) Contains(t time.Time) bool {
+	if !dr.OpenStart && t.Before(dr.Start) {
+		return false
+	}
+	if dr.OpenEnd {
+		return true
+	}
+	if dr.HasTime {
+		return !t.After(dr.End)
+	}
+	return t.Before(dr.exclusiveEnd())
+}
+
+// Reports whether the two ranges share any moment
+func (dr DateRange) Overlaps(other DateRange) bool {
+	startsBeforeOtherEnds := dr.OpenStart || other.OpenEnd || dr.Start.Before(other.exclusiveEnd()) ||
+		(other.HasTime && dr.Start.Equal(other.End))
+	otherStartsBeforeEnd := other.OpenStart || dr.OpenEnd || other.Start.Before(dr.exclusiveEnd()) ||
+		(dr.HasTime && other.Start.Equal(dr.End))
+	return startsBeforeOtherEnds && otherStartsBeforeEnd
+}
+
+// Calendar days touched by the range, in order. Nil for open or
+// reversed ranges.
+func (dr DateRange) Days() []time.Time {
+	if dr.IsOpen() || dr.End.Before(dr.Start) {
+		return nil
+	}
+
+	var ret []time.Time
+	y, m, d := dr.Start.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, dr.Start.Location())
+	for !day.After(dr.End) {
+		ret = append(ret, day)
+		day = day.AddDate(0, 0, 1)
+	}
+	return ret
+}
+
+// Length of the range; date ranges include their End day.
+// Zero for open ranges.
+func (dr DateRange) Duration() time.Duration {
+	if dr.IsOpen() {
+		return 0
+	}
+	return dr.exclusiveEnd().Sub(dr.Start)
+}
+
+// Splits a comma-separated list & resolves each element on its own,
+// so AllowDateRange & zone suffixes apply per element. The result is
+// sorted by start (open starts first) with duplicates removed.
+func (fp *FlagParser) convertDateList(input string, fi flag_info_key) ([]DateRange, error) {
+	var ret []DateRange
+	seen := make(map[string]bool)
+
+	for _, elem := range splitDateList(input) {
+		if len(strings.TrimSpace(elem)) == 0 {
+			return nil, &UnknownDateInputError{Input: input, Reason: "empty date list element"}
+		}
+		dr, err := fp.convertDateArg(elem, fi)
+		if err != nil {
+			return nil, err
+		}
+		if seen[dr.String()] {
+			continue
+		}
+		seen[dr.String()] = true
+		ret = append(ret, dr)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		a, b := ret[i], ret[j]
+		if a.OpenStart != b.OpenStart {
+			return a.OpenStart
+		}
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		return !a.OpenEnd && (b.OpenEnd || a.End.Before(b.End))
+	})
+	return ret, nil
+}
+
+// A year after a comma, ending the element or the range side
+var listYearPattern = regexp.MustCompile(`^\s*\d{4}(?:$|[\s,:]|\.\.)`)
+
+// Splits on commas, except those between a month name date & its
+// year ('march 14, 2023')
+func splitDateList(input string) []string {
+	var ret []string
+	for _, elem := range strings.Split(input, ",") {
+		if n := len(ret); n > 0 && listYearPattern.MatchString(elem) && strings.IndexFunc(ret[n-1], unicode.IsLetter) >= 0 {
+			ret[n-1] += "," + elem
+			continue
+		}
+		ret = append(ret, elem)
+	}
+	return ret
+}
+
+func joinDateList(list []DateRange) string {
+	var strs []string
+	for _, v := range list {
+		strs = append(strs, v.String())
+	}
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,123 @@
+package flagParser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTypedDateRange(t *testing.T) {
+	fp := NewFlagParser(_getRangeTestFlags(), []string{"-d", "-7d:10d", "-r", "9am..5pm"}, WithNowAs(returnNowString(), "2006-01-02"))
+	got, err := fp.ParseUserInput()
+	if err != nil {
+		t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+		return
+	}
+
+	dr, ok := fp.GetDateRange("-d")
+	if !ok || dr.String() != got[1] {
+		t.Errorf(">>>>FAILED: range doesn't serialise to parsed output. \nExp\t'%v' \nGot\t'%v'", got[1], dr)
+	}
+	if !dr.Contains(time.Date(2022, 03, 24, 15, 0, 0, 0, time.UTC)) || dr.Contains(time.Date(2022, 03, 25, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf(">>>>FAILED: date range should include the whole of its last day")
+	}
+	if len(dr.Days()) != 18 || dr.Duration() != 18*24*time.Hour {
+		t.Errorf(">>>>FAILED: wrong length. \nGot\t'%v' days, '%v'", len(dr.Days()), dr.Duration())
+	}
+
+	tr, ok := fp.GetDateRange("-r")
+	if !ok || tr.String() != got[3] || !tr.HasTime || tr.Duration() != 8*time.Hour || len(tr.Days()) != 1 {
+		t.Errorf(">>>>FAILED: timestamp range. \nExp\t'%v' \nGot\t'%v' '%v'", got[3], tr, tr.Duration())
+	}
+	if tr.Contains(time.Date(2022, 03, 14, 17, 1, 0, 0, time.UTC)) {
+		t.Errorf(">>>>FAILED: timestamp range should end at its end moment")
+	}
+
+	if _, ok := fp.GetDateRange("-b"); ok {
+		t.Errorf(">>>>FAILED: range reported for flag without one")
+	}
+}
+
+func TestOpenDateRange(t *testing.T) {
+	fp := NewFlagParser(_getRangeTestFlags(), []string{"-d", "-2y:"}, WithNowAs(returnNowString(), "2006-01-02"))
+	_, err := fp.ParseUserInput()
+	dr, ok := fp.GetDateRange("-d")
+
+	if err != nil || !ok || dr.OpenStart || !dr.OpenEnd || dr.String() != "2020-03-14:" {
+		t.Errorf(">>>>FAILED: open range. \nGot\t'%v' '%v'", dr, err)
+	}
+	if !dr.Contains(time.Date(2099, 01, 01, 0, 0, 0, 0, time.UTC)) || dr.Days() != nil || dr.Duration() != 0 {
+		t.Errorf(">>>>FAILED: open range should be unbounded")
+	}
+}
+
+func TestDateRangeOverlaps(t *testing.T) {
+	cases := []struct {
+		a, b, sep, layout string
+		overlaps          bool
+	}{
+		{"2022-03-01:2022-03-10", "2022-03-10:2022-03-20", ":", "2006-01-02", true},
+		{"2022-03-01:2022-03-10", "2022-03-11:", ":", "2006-01-02", false},
+		{"2022-03-01:2022-03-10", ":2022-03-01", ":", "2006-01-02", true},
+		{"2022-03-01T09:00..2022-03-01T10:00", "2022-03-01T10:00..2022-03-01T11:00", "..", "2006-01-02T15:04", true},
+		{"2022-03-01T09:00..2022-03-01T10:00", "2022-03-01T10:01..", "..", "2006-01-02T15:04", false},
+		{"2022-03-01T09:00..2022-03-01T10:00", "..2022-03-01T08:59", "..", "2006-01-02T15:04", false},
+	}
+
+	for _, c := range cases {
+		a, errA := ParseDateRange(c.a, c.sep, c.layout)
+		b, errB := ParseDateRange(c.b, c.sep, c.layout)
+		if errA != nil || errB != nil {
+			t.Errorf(">>>>FAILED: unable to parse '%v' or '%v'. \nGot\t'%v' '%v'", c.a, c.b, errA, errB)
+			continue
+		}
+		if a.String() != c.a || b.String() != c.b {
+			t.Errorf(">>>>FAILED: round trip. \nExp\t'%v' '%v' \nGot\t'%v' '%v'", c.a, c.b, a, b)
+		}
+		if a.Overlaps(b) != c.overlaps || b.Overlaps(a) != c.overlaps {
+			t.Errorf(">>>>FAILED: '%v' & '%v' overlap. \nExp\t'%v'", c.a, c.b, c.overlaps)
+		}
+	}
+
+	if _, err := ParseDateRange("2022-03-01", ":", "2006-01-02"); err == nil {
+		t.Errorf(">>>>FAILED: expected error for missing separator")
+	}
+}
+
+func TestTypedDateList(t *testing.T) {
+	fp := NewFlagParser(_getRangeTestFlags(), []string{"-l", "+1w,today,2022-05-01:2022-05-03"}, WithNowAs(returnNowString(), "2006-01-02"))
+	_, err := fp.ParseUserInput()
+	list, ok := fp.GetDateList("-l")
+
+	if err != nil || !ok || len(list) != 3 {
+		t.Errorf(">>>>FAILED: date list. \nGot\t'%v' '%v'", list, err)
+		return
+	}
+	if !list[0].Single || !list[0].Start.Equal(time.Date(2022, 03, 14, 0, 0, 0, 0, time.UTC)) || list[2].Single {
+		t.Errorf(">>>>FAILED: wrong list elements. \nGot\t'%v'", list)
+	}
+	if len(list[0].Days()) != 1 || len(list[2].Days()) != 3 {
+		t.Errorf(">>>>FAILED: single dates should cover one day")
+	}
+	if _, ok := fp.GetDateRange("-l"); ok {
+		t.Errorf(">>>>FAILED: list reported as a range")
+	}
+}
+
+func TestTypedResultsReset(t *testing.T) {
+	fp := NewFlagParser(_getRangeTestFlags(), []string{"-d", "-7d:10d"}, WithNowAs(returnNowString(), "2006-01-02"))
+	if _, err := fp.ParseUserInput(); err != nil {
+		t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+		return
+	}
+	if _, ok := fp.GetDateRange("-d"); !ok {
+		t.Errorf(">>>>FAILED: range missing after first parse")
+	}
+
+	fp.DateInputLayouts = []string{"02/01/2006"} //parsed output no longer fits
+	if _, err := fp.ParseUserInput(); err == nil {
+		t.Errorf(">>>>FAILED: expected second parse to fail")
+	}
+	if dr, ok := fp.GetDateRange("-d"); ok {
+		t.Errorf(">>>>FAILED: range kept from earlier parse. \nGot\t'%v'", dr)
+	}
+}
@@ -0,0 +1,657 @@
+package flagParser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Used when neither DateOutputLayout nor DateTimeLayout is set
+const defaultDateLayout = "2006-01-02"
+
+// Used when TimestampLayout isn't set
+const defaultTimestampLayout = "2006-01-02T15:04"
+
+// Layout used to format resolved dates. Falls back to
+// DateTimeLayout, then to 'YYYY-MM-DD'
+func (fp *FlagParser) outputLayout() string {
+	if fp.DateOutputLayout != "" {
+		return fp.DateOutputLayout
+	}
+	if fp.DateTimeLayout != "" {
+		return fp.DateTimeLayout
+	}
+	return defaultDateLayout
+}
+
+// Layouts literal date input is checked against. Defaults to
+// DateTimeLayout plus the output layout
+func (fp *FlagParser) inputLayouts() []string {
+	if len(fp.DateInputLayouts) > 0 {
+		return fp.DateInputLayouts
+	}
+
+	ret := []string{fp.outputLayout()}
+	if fp.DateTimeLayout != "" && fp.DateTimeLayout != ret[0] {
+		ret = append(ret, fp.DateTimeLayout)
+	}
+	return ret
+}
+
+// Layout used when a resolved date has a time of day
+func (fp *FlagParser) timestampLayout() string {
+	if fp.TimestampLayout != "" {
+		return fp.TimestampLayout
+	}
+	return defaultTimestampLayout
+}
+
+// Formats d with the parser's output layout
+func (fp *FlagParser) FormatDate(d time.Time) string {
+	return formatLayout(d, fp.outputLayout())
+}
+
+// Formats d with the parser's timestamp layout
+func (fp *FlagParser) FormatTimestamp(d time.Time) string {
+	return formatLayout(d, fp.timestampLayout())
+}
+
+// Checks args of DateTime flags for literal date strings
+// and date relative shorthand ('3d 9m 4y')
+func (fp *FlagParser) handleDates(input []string, ufLocations []int) ([]string, error) {
+
+	for _, v := range ufLocations {
+
+		flgInf, _ := fp.GetFlagInfoFromName(input[v])
+		if flgInf.flgType != DateTime {
+			continue
+		}
+		if fp.nowErr != nil {
+			return nil, fp.nowErr //relative dates would resolve against the zero time
+		}
+
+		if flgInf.allowList {
+			list, err := fp.convertDateList(input[v+1], flgInf)
+			if err != nil {
+				return nil, err
+			}
+			fp.dateLists[input[v]] = list
+			input[v+1] = joinDateList(list)
+			continue
+		}
+
+		dr, err := fp.convertDateArg(input[v+1], flgInf)
+		if err != nil {
+			return nil, err
+		}
+		if !dr.Single {
+			fp.dateRanges[input[v]] = dr
+		}
+		input[v+1] = dr.String()
+	}
+	return input, nil
+}
+
+// Resolves a single date or range. Single dates come back as a
+// one-day (or one-moment) range.
+func (fp *FlagParser) convertDateArg(input string, flgInf flag_info_key) (DateRange, error) {
+	arg, zone, err := splitZoneSuffix(input)
+	if err != nil {
+		return DateRange{}, err
+	}
+	flgInf.zoneSuffix = zone
+	arg = fp.Locale.translate(arg, false)
+
+	if flgInf.allowRange {
+		if dr, ok, err := fp.weekRange(arg, flgInf); ok || err != nil {
+			return dr, err
+		}
+	}
+
+	isRng, rng, exclusive := checkForComparison(arg)
+	if !isRng {
+		isRng, rng = checkForWordRange(arg)
+	}
+	if !isRng {
+		if isRng, rng, err = fp.splitRange(arg, flgInf); err != nil {
+			return DateRange{}, err
+		}
+	}
+	if isRng && !flgInf.allowRange {
+		return DateRange{}, &DateRangeNotAllowedError{}
+	}
+	if isRng {
+		return fp.convertRange(rng, exclusive, flgInf)
+	}
+
+	//keep using input as is
+	d, hasTime, err := fp.resolveDate(arg, flgInf)
+	if err != nil {
+		return DateRange{}, err
+	}
+	layout := fp.outputLayout()
+	if hasTime {
+		d, layout = d.In(fp.outputLocation(flgInf)), fp.timestampLayout()
+	}
+	return DateRange{Start: d, End: d, Single: true, HasTime: hasTime, Layout: layout}, nil
+}
+
+// Splits input on range separators. A ':' that belongs to a
+// time of day ('17:30') isn't treated as a separator, but is reported.
+func checkForDateRange(input string) (isRng bool, splt []string, hasClock bool) {
+	runes := []rune(input)
+	last, minutesEnd := 0, -1
+
+	for i, v := range runes {
+		if v != ':' {
+			continue
+		}
+		if isClockColon(runes, i, minutesEnd) {
+			minutesEnd, hasClock = i+3, true
+			continue
+		}
+		splt = append(splt, string(runes[last:i]))
+		last = i + 1
+	}
+	splt = append(splt, string(runes[last:]))
+
+	return len(splt) > 1, splt, hasClock
+}
+
+// A clock colon has 1-2 digits before it that aren't part of a date
+// or a previous time & exactly two digits after it
+func isClockColon(runes []rune, i, minutesEnd int) bool {
+	j := i
+	for j > 0 && unicode.IsDigit(runes[j-1]) {
+		j--
+	}
+	if i-j < 1 || i-j > 2 || i == minutesEnd {
+		return false
+	}
+	if j > 0 && strings.ContainsRune("-/.", runes[j-1]) {
+		return false
+	}
+
+	if i+2 >= len(runes) || !unicode.IsDigit(runes[i+1]) || !unicode.IsDigit(runes[i+2]) {
+		return false
+	}
+	return i+3 == len(runes) || !unicode.IsDigit(runes[i+3])
+}
+
+// Unit words recognised in relative date shorthand. 'm' is months,
+// so minutes are spelled 'min'.
+var dateUnits = []string{"y", "q", "m", "w", "d", "b", "h", "min"}
+
+// Checks for existence/location of date identifiers (see dateUnits) in
+// '3d1m5y' format. Populates date identifier map with relevant values.
+// Input that isn't a run of signed numbers & known units is treated as
+// a literal.
+func getDateMap(inputStr string) (mp map[string]int, literalDateStr bool, e error) {
+	mp = getEmptyDateMap()
+	runes := []rune(inputStr)
+	found := false
+
+	for i := 0; i < len(runes); {
+		start := i
+		for i < len(runes) && strings.ContainsRune("0123456789+-", runes[i]) {
+			i++
+		}
+		num := string(runes[start:i])
+
+		uStart := i
+		for i < len(runes) && unicode.IsLetter(runes[i]) {
+			i++
+		}
+		dateIdfr := string(runes[uStart:i])
+
+		if len(dateIdfr) == 0 {
+			if i < len(runes) || !found {
+				return mp, true, nil //e.g. '2022/03/14' or '12'
+			}
+			return nil, false, &UnknownDateInputError{Input: inputStr, Reason: fmt.Sprintf("number '%v' has no unit", num)} //e.g. '3d4'
+		}
+		if _, exists := mp[dateIdfr]; !exists {
+			return mp, true, nil //e.g. 'banana2022'
+		}
+
+		intPrefix, err := strconv.Atoi(num) //number (n) that comes before dateIdfr; e.g. if input = '3m', dateIdfr = 'm' & n = '3'
+		if err != nil {
+			return nil, false, &UnknownDateInputError{Input: inputStr, Reason: fmt.Sprintf("invalid number '%v' before '%v'", num, dateIdfr)}
+		}
+		mp[dateIdfr] = intPrefix
+		found = true
+	}
+	return mp, !found, e
+}
+
+func getEmptyDateMap() map[string]int {
+	mp := make(map[string]int)
+	for _, v := range dateUnits {
+		mp[v] = 0
+	}
+
+	return mp
+}
+
+// Timestamps are moved to the flag's output zone. Dates are left
+// alone; moving midnight between zones can change the day.
+func (fp *FlagParser) formatResolved(d time.Time, hasTime bool, fi flag_info_key) string {
+	if hasTime {
+		return fp.FormatTimestamp(d.In(fp.outputLocation(fi)))
+	}
+	return fp.FormatDate(d)
+}
+
+// Literal dates & timestamps are checked against the input layouts;
+// keywords & relative shorthand are applied to NowMoment. A trailing clock literal
+// ('9am', '17:30') sets the time of day on whatever precedes it.
+func (fp *FlagParser) resolveDate(input string, fi flag_info_key) (time.Time, bool, error) {
+
+	loc := fp.locationFor(fi)
+	d, ok, tsErr := parseLiteral(input, []string{fp.timestampLayout()}, loc)
+	if ok {
+		return d, true, nil
+	}
+	d, ok, calErr := parseLiteral(input, fp.inputLayouts(), loc)
+	if ok {
+		return d, false, nil
+	}
+	if calErr == nil {
+		calErr = tsErr //only reported if nothing else fits
+	}
+	if d, ok, err := parseWeekDate(input, loc); ok || err != nil {
+		return d, false, err
+	}
+	if d, ok, err := fp.parseOrderedDate(input, fi); ok || err != nil {
+		return d, false, err
+	}
+	input = fp.Locale.translate(input, true)
+
+	datePart, hh, mm, isClock, err := splitClock(input)
+	if err != nil && calErr != nil {
+		return time.Time{}, false, calErr //'2022-03-14T25:00' is a bad timestamp, not a bad clock
+	} else if err != nil {
+		return time.Time{}, false, err
+	}
+	if isClock {
+		d := fp.nowFor(fi)
+		if len(strings.TrimSpace(datePart)) > 0 {
+			d, _, err = fp.resolveDate(datePart, fi)
+			if err != nil {
+				return time.Time{}, false, err
+			}
+		}
+		return time.Date(d.Year(), d.Month(), d.Day(), hh, mm, 0, 0, d.Location()), true, nil
+	}
+
+	noSpaces := strings.ToLower(strings.ReplaceAll(input, " ", ""))
+	if d, ok := resolveKeyword(noSpaces, fp.nowFor(fi)); ok {
+		return d, false, nil
+	}
+	if d, ok, err := fp.parseMonthNameDate(input, fi); ok || err != nil {
+		return d, false, err
+	}
+	mp, literal, err := getDateMap(noSpaces)
+	if err != nil || literal {
+		if base, offsets, ok := splitDateExpression(input); ok {
+			return fp.resolveDateExpression(base, offsets, fi)
+		}
+		if calErr == nil {
+			if reason := fp.badDateOffset(input, fi); reason != "" {
+				return time.Time{}, false, &UnknownDateInputError{Input: strings.TrimSpace(input), Reason: reason}
+			}
+		}
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if literal && calErr != nil {
+		return time.Time{}, false, calErr
+	}
+	if literal {
+		return time.Time{}, false, &InvalidDateLiteralError{Input: strings.TrimSpace(input), Layouts: fp.inputLayouts()}
+	}
+
+	return applyDateMap(fp.nowFor(fi), mp, fp.overflowPolicy(fi), fp.holidaysFor(fi))
+}
+
+var (
+	dateExpressionPattern = regexp.MustCompile(`^(.*?[^+\-\s])\s*((?:[+-]\s*\d+\s*[\pL]+\s*)+)$`)
+	dateOffsetPattern     = regexp.MustCompile(`([+-])\s*(\d+)\s*([\pL]+)`)
+	offsetPartsPattern    = regexp.MustCompile(`^(\d*)\s*([\pL]*)`)
+)
+
+// Splits 'base+offset-offset...' where base is a literal, keyword
+// or 'now' & each offset is a signed number with a unit
+func splitDateExpression(input string) (base, offsets string, ok bool) {
+	m := dateExpressionPattern.FindStringSubmatch(strings.TrimSpace(input))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], strings.ToLower(m[2]), true //base may be a timestamp ('T09:00')
+}
+
+// Why the offsets after a literal or keyword base don't parse
+// ('eom-3', 'eom+', '2022-03-01++2d'); "" if input isn't one
+func (fp *FlagParser) badDateOffset(input string, fi flag_info_key) string {
+	input = strings.TrimSpace(input)
+	for i := len(input) - 1; i > 0; i-- {
+		if input[i] != '+' && input[i] != '-' {
+			continue
+		}
+		reason := offsetProblem(input[i:])
+		if reason != "" && fp.isDateBase(input[:i], fi) {
+			return reason
+		}
+	}
+	return ""
+}
+
+// A date literal, timestamp or keyword; unlike resolveDate it
+// doesn't look for offsets
+func (fp *FlagParser) isDateBase(base string, fi flag_info_key) bool {
+	layouts := append([]string{fp.timestampLayout()}, fp.inputLayouts()...)
+	if _, ok, _ := parseLiteral(base, layouts, fp.locationFor(fi)); ok {
+		return true
+	}
+	base = fp.Locale.translate(base, true)
+	_, ok := resolveKeyword(strings.ToLower(strings.ReplaceAll(base, " ", "")), fp.nowFor(fi))
+	return ok
+}
+
+// The first thing wrong with a run of '+2d-1w' offsets, or ""
+func offsetProblem(offsets string) string {
+	for s := strings.TrimSpace(offsets); s != ""; {
+		if s[0] != '+' && s[0] != '-' {
+			return fmt.Sprintf("expected a signed offset at '%v'", s)
+		}
+		rest := strings.TrimSpace(s[1:])
+		switch {
+		case rest == "":
+			return fmt.Sprintf("dangling sign in offset '%v'", s)
+		case rest[0] == '+' || rest[0] == '-':
+			return fmt.Sprintf("doubled sign in offset '%v'", s)
+		}
+
+		m := offsetPartsPattern.FindStringSubmatch(rest)
+		switch {
+		case m[1] == "":
+			return fmt.Sprintf("missing number in offset '%v'", s)
+		case m[2] == "":
+			return fmt.Sprintf("missing unit in offset '%v'", s)
+		}
+		s = strings.TrimSpace(rest[len(m[0]):])
+	}
+	return ""
+}
+
+// Resolves base, then applies each offset in turn. Offsets with the
+// same unit add up ('eom+1d+1d' == 'eom+2d').
+func (fp *FlagParser) resolveDateExpression(base, offsets string, fi flag_info_key) (time.Time, bool, error) {
+	d, hasTime, err := fp.resolveDate(base, fi)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	mp := getEmptyDateMap()
+	for _, m := range dateOffsetPattern.FindAllStringSubmatch(offsets, -1) {
+		if _, exists := mp[m[3]]; !exists {
+			return time.Time{}, false, &UnknownDateInputError{Input: base + offsets, Reason: fmt.Sprintf("unknown unit '%v' in offset '%v'", m[3], m[0])}
+		}
+		n, _ := strconv.Atoi(m[2])
+		if m[1] == "-" {
+			n = -n
+		}
+		mp[m[3]] += n
+	}
+
+	d, offsetHasTime, err := applyDateMap(d, mp, fp.overflowPolicy(fi), fp.holidaysFor(fi))
+	return d, hasTime || offsetHasTime, err
+}
+
+// Applies the offsets in mp to base. Returns true if any
+// offset is smaller than a day.
+func applyDateMap(base time.Time, mp map[string]int, policy MonthOverflowPolicy, cal HolidayCalendar) (time.Time, bool, error) {
+	//quarters & weeks are just multiples of months & days
+	yInt := mp["y"]
+	mInt := mp["m"] + 3*mp["q"]
+	dInt := mp["d"] + 7*mp["w"]
+
+	ret, err := addMonths(base, yInt, mInt, policy)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	ret = ret.AddDate(0, 0, dInt)
+	ret, err = addBusinessDays(ret, mp["b"], cal)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	offset := time.Duration(mp["h"])*time.Hour + time.Duration(mp["min"])*time.Minute
+	return ret.Add(offset), offset != 0, nil
+}
+
+// How year & month arithmetic handles days missing from the target
+// month, e.g. Jan 31 + 1m
+type MonthOverflowPolicy int
+
+const (
+	OverflowDefault   MonthOverflowPolicy = iota //flags inherit the parser's; parser uses OverflowNormalise
+	OverflowNormalise                            //Go's AddDate; Jan 31 + 1m = Mar 3
+	OverflowClamp                                //last day of target month; Jan 31 + 1m = Feb 28
+	OverflowReject                               //MonthOverflowError
+)
+
+// Flag policy takes precedence over the parser's
+func (fp *FlagParser) overflowPolicy(fi flag_info_key) MonthOverflowPolicy {
+	if fi.monthOverflow != OverflowDefault {
+		return fi.monthOverflow
+	}
+	return fp.MonthOverflow
+}
+
+func addMonths(base time.Time, years, months int, policy MonthOverflowPolicy) (time.Time, error) {
+	y, m, d := base.Date()
+	firstOfTarget := time.Date(y+years, m+time.Month(months), 1, 0, 0, 0, 0, base.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+
+	if d <= lastDay || policy == OverflowDefault || policy == OverflowNormalise {
+		return base.AddDate(years, months, 0), nil
+	}
+	if policy == OverflowReject {
+		return time.Time{}, &MonthOverflowError{Base: base, Years: years, Months: months}
+	}
+
+	hh, mm, ss := base.Clock()
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), lastDay, hh, mm, ss, base.Nanosecond(), base.Location()), nil
+}
+
+var (
+	clock12Pattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	clock24Pattern = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+)
+
+// Splits a clock literal ('9am', '5:30 pm', '17:30') from the start
+// or end of input. Returns whatever else there was as datePart. A
+// clock anywhere else is an error.
+func splitClock(input string) (datePart string, hh, mm int, isClock bool, e error) {
+	trimmed := strings.ToLower(strings.TrimSpace(input))
+
+	var tokens []string
+	for _, tok := range strings.Fields(trimmed) {
+		if n := len(tokens); n > 0 && (tok == "am" || tok == "pm") {
+			tokens[n-1] += tok //'9 am'
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+	if len(tokens) == 0 {
+		return input, 0, 0, false, nil
+	}
+
+	last := len(tokens) - 1
+	for _, i := range []int{last, 0} {
+		hh, mm, ok, err := parseClock(tokens[i], trimmed)
+		if err != nil {
+			return "", 0, 0, false, err
+		}
+		if ok {
+			rest := append(append([]string{}, tokens[:i]...), tokens[i+1:]...)
+			return strings.Join(rest, " "), hh, mm, true, nil
+		}
+	}
+	for i := 1; i < last; i++ {
+		tok := tokens[i]
+		if _, _, ok, _ := parseClock(tok, trimmed); ok {
+			return "", 0, 0, false, &UnknownDateInputError{Input: trimmed, Reason: fmt.Sprintf("time of day '%v' must come before or after the date", tok)}
+		}
+	}
+	return input, 0, 0, false, nil
+}
+
+// Reads a single clock token. input is only used in errors.
+func parseClock(tok, input string) (hh, mm int, ok bool, e error) {
+	outOfRange := &UnknownDateInputError{Input: input, Reason: "time of day out of range"}
+
+	if m := clock12Pattern.FindStringSubmatch(tok); m != nil {
+		hh, _ = strconv.Atoi(m[1])
+		if len(m[2]) > 0 {
+			mm, _ = strconv.Atoi(m[2])
+		}
+		if hh < 1 || hh > 12 || mm > 59 {
+			return 0, 0, false, outOfRange
+		}
+		hh = hh % 12
+		if m[3] == "pm" {
+			hh += 12
+		}
+		return hh, mm, true, nil
+	}
+
+	if m := clock24Pattern.FindStringSubmatch(tok); m != nil {
+		hh, _ = strconv.Atoi(m[1])
+		mm, _ = strconv.Atoi(m[2])
+		if hh > 23 || mm > 59 {
+			return 0, 0, false, outOfRange
+		}
+		return hh, mm, true, nil
+	}
+	return 0, 0, false, nil
+}
+
+// Moves d by n weekdays, skipping Saturdays, Sundays & any
+// holidays in cal. Negative n moves backwards.
+func addBusinessDays(d time.Time, n int, cal HolidayCalendar) (time.Time, error) {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+
+	//any 7 days hold exactly 5 weekdays, so jump whole weeks & add
+	//back the holidays in them. Leave at least one day to step to
+	for n > 5 {
+		weeks := (n - 1) / 5
+		end := d.AddDate(0, 0, 7*weeks*step)
+		h := weekdayHolidays(d, end, cal)
+		if h == 5*weeks {
+			break //no progress; the day by day loop stops long runs
+		}
+		d, n = end, n-5*weeks+h
+	}
+
+	skipped := 0
+	for n > 0 {
+		d = d.AddDate(0, 0, step)
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || (cal != nil && cal.IsHoliday(d)) {
+			skipped++
+			if skipped > maxHolidayRun {
+				return time.Time{}, &UnknownDateInputError{Input: StringFromDate(d), Reason: "no business days for over a year"}
+			}
+			continue
+		}
+		n--
+		skipped = 0
+	}
+	return d, nil
+}
+
+// Counts holidays on weekdays after from, up to & including to,
+// in either direction
+func weekdayHolidays(from, to time.Time, cal HolidayCalendar) int {
+	if cal == nil {
+		return 0
+	}
+	lo, hi := from, to
+	if to.Before(from) {
+		lo, hi = to.AddDate(0, 0, -1), from.AddDate(0, 0, -1)
+	}
+	isWeekday := func(d time.Time) bool {
+		return d.Weekday() != time.Saturday && d.Weekday() != time.Sunday
+	}
+
+	count := 0
+	if hl, ok := cal.(HolidayList); ok { //no need to check every day
+		loKey, hiKey := StringFromDate(lo), StringFromDate(hi)
+		for k := range hl {
+			d, err := time.Parse(defaultDateLayout, k)
+			if err == nil && k > loKey && k <= hiKey && isWeekday(d) {
+				count++
+			}
+		}
+		return count
+	}
+
+	for d := lo.AddDate(0, 0, 1); !d.After(hi); d = d.AddDate(0, 0, 1) {
+		if isWeekday(d) && cal.IsHoliday(d) {
+			count++
+		}
+	}
+	return count
+}
+
+// Tries input (as given and with spaces removed) against
+// each of the given layouts, in loc. If none fit but one got as far
+// as a value out of range ('2022-02-30'), that's returned as well.
+func parseLiteral(input string, layouts []string, loc *time.Location) (time.Time, bool, error) {
+	var calErr error
+	trimmed := strings.TrimSpace(input)
+	candidates := []string{trimmed}
+	if noSpaces := strings.ReplaceAll(trimmed, " ", ""); noSpaces != trimmed {
+		candidates = append(candidates, noSpaces)
+	}
+
+	for _, layout := range layouts {
+		for _, c := range candidates {
+			d, err := parseLayout(layout, c, loc)
+			if err == nil {
+				return d, true, nil
+			}
+			if calErr == nil {
+				calErr = calendarError(trimmed, err)
+			}
+		}
+	}
+	return time.Time{}, false, calErr
+}
+
+// Picks the component out of time's 'month out of range' etc.
+func calendarError(input string, err error) error {
+	if ce, ok := err.(*InvalidCalendarDateError); ok {
+		return ce
+	}
+	pe, ok := err.(*time.ParseError)
+	if !ok || !strings.HasSuffix(pe.Message, " out of range") {
+		return nil
+	}
+	component := strings.TrimSuffix(strings.TrimPrefix(pe.Message, ": "), " out of range")
+	return &InvalidCalendarDateError{Input: input, Component: component}
+}
+
+// Formats d as 'YYYY-MM-DD'. Use FlagParser.FormatDate
+// to respect a configured layout
+func StringFromDate(d time.Time) string {
+	return d.Format(defaultDateLayout)
+}
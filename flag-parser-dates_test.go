@@ -0,0 +1,472 @@
+package flagParser
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func _getDateLayoutTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "banana2022"},
+		expected:    []string{},
+		name:        "literal not matching layout",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &InvalidDateLiteralError{},
+	}, {
+		args:        []string{"-d", "2022-04-17:banana"},
+		expected:    []string{},
+		name:        "range with invalid literal",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &MalformedDateRangeError{},
+	}, {
+		args:        []string{"-d", "2d"},
+		expected:    []string{"-d", "16/03/2022"},
+		name:        "relative date with output layout",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+		configure:   func(fp *FlagParser) { fp.DateOutputLayout = "02/01/2006" },
+	}, {
+		args:        []string{"-d", "2022-04-17:1/5/2022"},
+		expected:    []string{"-d", "17/04/2022:01/05/2022"},
+		name:        "literal range normalised to output layout",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+		configure: func(fp *FlagParser) {
+			fp.DateOutputLayout = "02/01/2006"
+			fp.DateInputLayouts = []string{"2006-01-02", "2/1/2006"}
+		},
+	}, {
+		args:        []string{"-d", "17", "Apr", "2022"},
+		expected:    []string{"-d", "2022-04-17"},
+		name:        "spaced literal with month name layout",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+		configure: func(fp *FlagParser) {
+			fp.DateOutputLayout = "2006-01-02"
+			fp.DateInputLayouts = []string{"2 Jan 2006"}
+		},
+	}, {
+		args:        []string{"-d", "2022-04-17"},
+		expected:    []string{},
+		name:        "literal only accepted in configured layouts",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &InvalidDateLiteralError{},
+		configure:   func(fp *FlagParser) { fp.DateInputLayouts = []string{"02/01/2006"} },
+	}}
+}
+
+func TestDateLayouts(t *testing.T) {
+	os.Setenv("MAX_LENGTH", "2000")
+	os.Setenv("MAX_TAG_LENGTH", "10")
+	os.Setenv("MAX_INT_DIGITS", "4")
+
+	tcs := _getDateLayoutTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func _getDateUnitTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "1w2d"},
+		expected:    []string{"-d", "2022-03-23"},
+		name:        "weeks and days",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "1q"},
+		expected:    []string{"-d", "2022-06-14"},
+		name:        "quarter",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "-3b"},
+		expected:    []string{"-d", "2022-03-09"},
+		name:        "negative business days",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "5b"},
+		expected:    []string{"-d", "2022-03-21"},
+		name:        "business days over weekend",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "1w", "-1b"},
+		expected:    []string{"-d", "2022-03-18"},
+		name:        "business days applied after week",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "-1q:2w"},
+		expected:    []string{"-d", "2021-12-14:2022-03-28"},
+		name:        "quarter and week range",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "w"},
+		expected:    []string{},
+		name:        "week unit without number",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &UnknownDateInputError{},
+	}}
+}
+
+func TestDateUnits(t *testing.T) {
+	os.Setenv("MAX_LENGTH", "2000")
+	os.Setenv("MAX_TAG_LENGTH", "10")
+	os.Setenv("MAX_INT_DIGITS", "4")
+
+	tcs := _getDateUnitTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func _getTimeOfDayTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "9am"},
+		expected:    []string{"-d", "2022-03-14T09:00"},
+		name:        "clock literal today",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "17:30"},
+		expected:    []string{"-d", "2022-03-14T17:30"},
+		name:        "24 hour clock literal",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "9am", "tomorrow"},
+		expected:    []string{"-d", "2022-03-15T09:00"},
+		name:        "clock before the date",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "mar", "14:15", "mar"},
+		expected:    []string{},
+		name:        "clock inside the date",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &UnknownDateInputError{},
+	}, {
+		args:        []string{"-d", "mar14:15"},
+		expected:    []string{},
+		name:        "clock joined to a word",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &InvalidDateLiteralError{},
+	}, {
+		args:        []string{"-d", "tomorrow", "14:00"},
+		expected:    []string{"-d", "2022-03-15T14:00"},
+		name:        "tomorrow with clock literal",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "2d", "5:30pm"},
+		expected:    []string{"-d", "2022-03-16T17:30"},
+		name:        "relative date with clock literal",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "3h30min"},
+		expected:    []string{"-d", "2022-03-14T03:30"},
+		name:        "hour and minute offsets",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "1m"},
+		expected:    []string{"-d", "2022-04-14"},
+		name:        "m still means months",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "-1d2h"},
+		expected:    []string{"-d", "2022-03-13T02:00"},
+		name:        "days and hours",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "2022-03-20T17:30"},
+		expected:    []string{"-d", "2022-03-20T17:30"},
+		name:        "literal timestamp",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "9:00..17:00"},
+		expected:    []string{"-d", "2022-03-14T09:00..2022-03-14T17:00"},
+		name:        "clock range",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "9:00:17:00"},
+		expected:    []string{},
+		name:        "clock range split on colon",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &AmbiguousRangeSeparatorError{},
+	}, {
+		args:        []string{"-z", "2022-03-14T09:00:2022-03-14T17:00"},
+		expected:    []string{},
+		name:        "timestamp range split on colon",
+		systemFlags: _getRangeTestFlags,
+		err:         &AmbiguousRangeSeparatorError{},
+	}, {
+		args:        []string{"-d", "2022-03-01..5d"},
+		expected:    []string{"-d", "2022-03-01:2022-03-19"},
+		name:        "date range split on dots",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "9am:5pm"},
+		expected:    []string{"-d", "2022-03-14T09:00..2022-03-14T17:00"},
+		name:        "12 hour clock range",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         nil,
+	}, {
+		args:        []string{"-d", "25:00"},
+		expected:    []string{},
+		name:        "hour out of range",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &UnknownDateInputError{},
+	}, {
+		args:        []string{"-d", "13pm"},
+		expected:    []string{},
+		name:        "12 hour clock out of range",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &UnknownDateInputError{},
+	}, {
+		args:        []string{"-d", "3d4"},
+		expected:    []string{},
+		name:        "trailing number without unit",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &UnknownDateInputError{},
+	}}
+}
+
+func TestTimeOfDay(t *testing.T) {
+	os.Setenv("MAX_LENGTH", "2000")
+	os.Setenv("MAX_TAG_LENGTH", "10")
+	os.Setenv("MAX_INT_DIGITS", "4")
+
+	tcs := _getTimeOfDayTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func _getDateExpressionTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "2022-03-01+2w"},
+		expected:    []string{"-d", "2022-03-15"},
+		name:        "literal plus weeks",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "eom-3d"},
+		expected:    []string{"-d", "2022-03-28"},
+		name:        "keyword minus days",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "now+1w"},
+		expected:    []string{"-d", "2022-03-21"},
+		name:        "now plus week",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "friday", "+", "1w", "-", "1d"},
+		expected:    []string{"-d", "2022-03-24"},
+		name:        "spaced offsets",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "eom+1d+1d"},
+		expected:    []string{"-d", "2022-04-02"},
+		name:        "repeated units add up",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "2022-03-01 17:00+2h"},
+		expected:    []string{"-d", "2022-03-01T19:00"},
+		name:        "timestamp plus hours",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "2022-03-14T09:00+2h"},
+		expected:    []string{"-d", "2022-03-14T11:00"},
+		name:        "timestamp layout base",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "2022-03-01-1m:eom+1w"},
+		expected:    []string{"-d", "2022-02-01:2022-04-07"},
+		name:        "expressions on both sides of range",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "2022-03-01+2x"},
+		expected:    []string{},
+		name:        "unknown offset unit",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &UnknownDateInputError{},
+	}, {
+		args:        []string{"-d", "banana+2d"},
+		expected:    []string{},
+		name:        "invalid base",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &InvalidDateLiteralError{},
+	}}
+}
+
+func TestDateExpressions(t *testing.T) {
+	os.Setenv("MAX_LENGTH", "2000")
+	os.Setenv("MAX_TAG_LENGTH", "10")
+	os.Setenv("MAX_INT_DIGITS", "4")
+
+	tcs := _getDateExpressionTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestDateExpressionErrorDetail(t *testing.T) {
+	fp := NewFlagParser(_getCanonicalFlagsForGodoGettingTests(), []string{"-d", "x"}, WithNowAs(returnNowString(), "2006-01-02"))
+	exp := map[string]string{
+		"2022-03-01+2x":  "unknown unit 'x' in offset '+2x'",
+		"3d4":            "number '4' has no unit",
+		"1+y":            "invalid number '1+' before 'y'",
+		"eom-3":          "missing unit in offset '-3'",
+		"eom+":           "dangling sign in offset '+'",
+		"eom+2d-":        "dangling sign in offset '-'",
+		"2022-03-01++2d": "doubled sign in offset '++2d'",
+		"today+1w-3":     "missing unit in offset '-3'",
+	}
+
+	for input, reason := range exp {
+		_, _, err := fp.resolveDate(input, flag_info_key{})
+		ue, ok := err.(*UnknownDateInputError)
+		if !ok || ue.Reason != reason {
+			t.Errorf(">>>>FAILED: input '%v'. \nExp\t'%v' \nGot\t'%v'", input, reason, err)
+		}
+	}
+}
+
+func _getMonthOverflowTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "2022-01-31+1m"},
+		expected:    []string{"-d", "2022-03-03"},
+		name:        "go normalisation by default",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "2022-01-31+1m"},
+		expected:    []string{"-d", "2022-02-28"},
+		name:        "clamped by parser policy",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		configure:   func(fp *FlagParser) { fp.MonthOverflow = OverflowClamp },
+	}, {
+		args:        []string{"-d", "2022-01-31+1m"},
+		expected:    []string{},
+		name:        "rejected by parser policy",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &MonthOverflowError{},
+		configure:   func(fp *FlagParser) { fp.MonthOverflow = OverflowReject },
+	}, {
+		args:        []string{"-d", "2022-01-31:+1m"},
+		expected:    []string{"-d", "2022-01-31:2022-02-28"},
+		name:        "anchored range end clamped",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		configure:   func(fp *FlagParser) { fp.MonthOverflow = OverflowClamp },
+	}, {
+		args:     []string{"-d", "2022-01-30+1m"},
+		expected: []string{"-d", "2022-03-02"},
+		name:     "flag policy overrides parser policy",
+		systemFlags: func() []FlagInfo {
+			flags := _getCanonicalFlagsForGodoGettingTests()
+			flags[3].MonthOverflow = OverflowNormalise //-d
+			return flags
+		},
+		configure: func(fp *FlagParser) { fp.MonthOverflow = OverflowReject },
+	}}
+}
+
+func TestMonthOverflow(t *testing.T) {
+	os.Setenv("MAX_LENGTH", "2000")
+	os.Setenv("MAX_TAG_LENGTH", "10")
+	os.Setenv("MAX_INT_DIGITS", "4")
+
+	tcs := _getMonthOverflowTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestMonthEndsAcrossYears(t *testing.T) {
+	for _, year := range []int{2023, 2024} { //2024 is a leap year
+		for m := time.January; m <= time.December; m++ {
+			monthEnd := time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC)
+			nextMonthEnd := time.Date(year, m+2, 0, 0, 0, 0, 0, time.UTC)
+			overflows := monthEnd.Day() > nextMonthEnd.Day()
+
+			clamped, err := addMonths(monthEnd, 0, 1, OverflowClamp)
+			if err != nil || clamped.After(nextMonthEnd) || (overflows && !clamped.Equal(nextMonthEnd)) {
+				t.Errorf(">>>>FAILED: clamp %v + 1m. \nGot\t'%v' '%v'", StringFromDate(monthEnd), StringFromDate(clamped), err)
+			}
+
+			normalised, _ := addMonths(monthEnd, 0, 1, OverflowNormalise)
+			if !normalised.Equal(monthEnd.AddDate(0, 1, 0)) {
+				t.Errorf(">>>>FAILED: normalise %v + 1m. \nGot\t'%v'", StringFromDate(monthEnd), StringFromDate(normalised))
+			}
+
+			_, err = addMonths(monthEnd, 0, 1, OverflowReject)
+			if overflows != (err != nil) {
+				t.Errorf(">>>>FAILED: reject %v + 1m. \nGot\t'%v'", StringFromDate(monthEnd), err)
+			}
+		}
+	}
+
+	leapDay := time.Date(2024, 02, 29, 0, 0, 0, 0, time.UTC)
+	exp := map[MonthOverflowPolicy]string{OverflowNormalise: "2025-03-01", OverflowClamp: "2025-02-28"}
+	for policy, want := range exp {
+		got, err := addMonths(leapDay, 1, 0, policy)
+		if err != nil || StringFromDate(got) != want {
+			t.Errorf(">>>>FAILED: leap day + 1y. \nExp\t'%v' \nGot\t'%v' '%v'", want, StringFromDate(got), err)
+		}
+	}
+	if _, err := addMonths(leapDay, 1, 0, OverflowReject); err == nil {
+		t.Errorf(">>>>FAILED: leap day + 1y should be rejected")
+	}
+	if got, _ := addMonths(leapDay, 4, 0, OverflowReject); StringFromDate(got) != "2028-02-29" {
+		t.Errorf(">>>>FAILED: leap day + 4y. \nGot\t'%v'", StringFromDate(got))
+	}
+}
+
+func TestCalendarValidation(t *testing.T) {
+	fp := NewFlagParser(_getCanonicalFlagsForGodoGettingTests(), []string{"-d", "x"}, WithNowAs(returnNowString(), "2006-01-02"))
+	exp := map[string]string{
+		"2022-02-30":       "day",
+		"2022-02-29":       "day",
+		"2022-13-01":       "month",
+		"2022-00-10":       "month",
+		"2022-04-31":       "day",
+		"2022-03-14T25:00": "hour",
+		"2022-02-30+1d":    "day",
+	}
+
+	for input, component := range exp {
+		_, _, err := fp.resolveDate(input, flag_info_key{})
+		ce, ok := err.(*InvalidCalendarDateError)
+		if !ok || ce.Component != component {
+			t.Errorf(">>>>FAILED: input '%v'. \nExp\t'%v' out of range \nGot\t'%v'", input, component, err)
+		}
+	}
+
+	for _, input := range []string{"2024-02-29", "2000-02-29", "2022-12-31"} {
+		if _, _, err := fp.resolveDate(input, flag_info_key{}); err != nil {
+			t.Errorf(">>>>FAILED: valid date '%v' rejected. \nGot\t'%v'", input, err)
+		}
+	}
+	if _, _, err := fp.resolveDate("1900-02-29", flag_info_key{}); err == nil {
+		t.Errorf(">>>>FAILED: 1900 isn't a leap year")
+	}
+}
@@ -0,0 +1,218 @@
+package flagParser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration args are accepted in three forms:
+//
+//	ISO 8601   'P1DT2H', 'PT90M', '-P1W'
+//	Go         '1h30m', '2m30s', '1.5h', '500ms'
+//	shorthand  '3d2m', '1y 2w', '45min' (the DateTime units)
+//
+// 'm' is months in the shorthand & minutes in Go durations. An input
+// is only read as a Go duration if it has a unit the shorthand lacks
+// (ns, us, µs, ms, s), an 'm' straight after an 'h' ('1h30m'), a
+// fraction ('1.5h', '2.5m'), or only hours ('2h', the same either
+// way). Otherwise 'm' is months: '30m' is thirty months; use '30min'
+// or 'PT30M' for minutes. In ISO 8601 the T separator decides, as usual.
+//
+// A parser's Locale is applied before the Go check, so its units
+// mean what they do on DateTime flags; French '2s' is two semaines,
+// not two seconds. Use 'PT2S' for seconds there.
+//
+// Quarters become 3 months & weeks 7 days. Business days ('b') are
+// rejected as their length depends on where they start.
+
+// Duration split into calendar parts, whose length depends on when
+// they're applied, & an exact clock part
+type CalendarDuration struct {
+	Years  int
+	Months int
+	Days   int
+	Clock  time.Duration
+}
+
+var (
+	isoDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+	goDurationPattern  = regexp.MustCompile(`^[+-]?(?:\d*\.?\d+(?:ns|us|µs|ms|s|m|h))+$`)
+	goUnitPattern      = regexp.MustCompile(`(ns|us|µs|ms|s|m|h)`)
+)
+
+// Parses any of the forms above
+func ParseCalendarDuration(input string) (CalendarDuration, error) {
+	return parseCalendarDuration(input, nil)
+}
+
+// ISO 8601 is never translated
+func parseCalendarDuration(input string, loc *Locale) (CalendarDuration, error) {
+	noSpaces := strings.ReplaceAll(strings.TrimSpace(input), " ", "")
+	fail := func(reason string) (CalendarDuration, error) {
+		return CalendarDuration{}, &InvalidDurationError{Input: input, Reason: reason}
+	}
+	if noSpaces == "" {
+		return fail("empty duration")
+	}
+
+	upper := strings.ToUpper(noSpaces)
+	if strings.HasPrefix(strings.TrimLeft(upper, "+-"), "P") {
+		return parseISODuration(input, upper)
+	}
+
+	lower := strings.ToLower(noSpaces)
+	lower = strings.ReplaceAll(strings.ToLower(loc.translate(lower, true)), " ", "")
+	if isGoDuration(lower) {
+		d, err := time.ParseDuration(lower)
+		if err != nil {
+			return fail(err.Error())
+		}
+		return CalendarDuration{Clock: d}, nil
+	}
+
+	mp, literal, err := getDateMap(lower)
+	if ue, ok := err.(*UnknownDateInputError); ok {
+		return fail(ue.Reason)
+	} else if err != nil {
+		return fail(err.Error())
+	}
+	if literal {
+		return fail("expected ISO 8601 ('P1DT2H'), Go ('1h30m') or shorthand ('3d2h')")
+	}
+	if mp["b"] != 0 {
+		return fail("business days have no fixed length")
+	}
+
+	cd := CalendarDuration{Years: mp["y"], Months: mp["m"] + 3*mp["q"], Days: mp["d"] + 7*mp["w"],
+		Clock: time.Duration(mp["h"])*time.Hour + time.Duration(mp["min"])*time.Minute}
+	if cd.mixedSigns() {
+		return fail("parts have different signs")
+	}
+	return cd, nil
+}
+
+// See the rules at the top of the file
+func isGoDuration(input string) bool {
+	if !goDurationPattern.MatchString(input) {
+		return false
+	}
+	if strings.Contains(input, ".") {
+		return true //the shorthand only takes whole numbers
+	}
+	units := goUnitPattern.FindAllString(input, -1)
+	onlyHours := true
+	for i, u := range units {
+		onlyHours = onlyHours && u == "h"
+		if u != "m" && u != "h" {
+			return true
+		}
+		if u == "m" && i > 0 && units[i-1] == "h" {
+			return true
+		}
+	}
+	return onlyHours
+}
+
+func parseISODuration(input, upper string) (CalendarDuration, error) {
+	m := isoDurationPattern.FindStringSubmatch(upper)
+	if m == nil || strings.HasSuffix(upper, "P") || strings.HasSuffix(upper, "T") {
+		return CalendarDuration{}, &InvalidDurationError{Input: input, Reason: "malformed ISO 8601 duration"}
+	}
+
+	num := func(s string) int {
+		v, _ := strconv.Atoi(s) //empty parts are zero
+		return v
+	}
+	secs, _ := strconv.ParseFloat(strings.Replace(m[8], ",", ".", 1), 64)
+
+	cd := CalendarDuration{Years: num(m[2]), Months: num(m[3]), Days: num(m[5]) + 7*num(m[4]),
+		Clock: time.Duration(num(m[6]))*time.Hour + time.Duration(num(m[7]))*time.Minute + time.Duration(secs*float64(time.Second))}
+	if m[1] == "-" {
+		cd = cd.negate()
+	}
+	return cd, nil
+}
+
+func (cd CalendarDuration) negate() CalendarDuration {
+	return CalendarDuration{Years: -cd.Years, Months: -cd.Months, Days: -cd.Days, Clock: -cd.Clock}
+}
+
+func (cd CalendarDuration) mixedSigns() bool {
+	pos, neg := false, false
+	for _, v := range []int64{int64(cd.Years), int64(cd.Months), int64(cd.Days), int64(cd.Clock)} {
+		pos, neg = pos || v > 0, neg || v < 0
+	}
+	return pos && neg
+}
+
+// True if the duration has years, months or days
+func (cd CalendarDuration) IsCalendar() bool {
+	return cd.Years != 0 || cd.Months != 0 || cd.Days != 0
+}
+
+// The exact length, if there are no calendar parts
+func (cd CalendarDuration) Exact() (time.Duration, bool) {
+	return cd.Clock, !cd.IsCalendar()
+}
+
+// Applies the calendar parts with AddDate, then the clock part
+func (cd CalendarDuration) AddTo(t time.Time) time.Time {
+	return t.AddDate(cd.Years, cd.Months, cd.Days).Add(cd.Clock)
+}
+
+// Canonical ISO 8601 form, e.g. 'P1Y2M3DT4H5M6.5S'. Weeks are
+// written as days & a zero duration as 'PT0S'.
+func (cd CalendarDuration) String() string {
+	sign := ""
+	if cd.Years < 0 || cd.Months < 0 || cd.Days < 0 || cd.Clock < 0 {
+		sign, cd = "-", cd.negate()
+	}
+
+	var sb strings.Builder
+	sb.WriteString(sign + "P")
+	for _, p := range []struct {
+		v    int
+		unit string
+	}{{cd.Years, "Y"}, {cd.Months, "M"}, {cd.Days, "D"}} {
+		if p.v != 0 {
+			fmt.Fprintf(&sb, "%v%v", p.v, p.unit)
+		}
+	}
+
+	if cd.Clock != 0 || !cd.IsCalendar() {
+		sb.WriteString("T")
+		h, rem := cd.Clock/time.Hour, cd.Clock%time.Hour
+		m, rem := rem/time.Minute, rem%time.Minute
+		if h != 0 {
+			fmt.Fprintf(&sb, "%vH", int64(h))
+		}
+		if m != 0 {
+			fmt.Fprintf(&sb, "%vM", int64(m))
+		}
+		if rem != 0 || cd.Clock == 0 {
+			sb.WriteString(strconv.FormatFloat(rem.Seconds(), 'f', -1, 64) + "S")
+		}
+	}
+	return sb.String()
+}
+
+// Parses the args of Duration flags & replaces them with their ISO 8601 form
+func (fp *FlagParser) handleDurations(input []string, ufLocations []int) ([]string, error) {
+	for _, v := range ufLocations {
+		flgInf, _ := fp.GetFlagInfoFromName(input[v])
+		if flgInf.flgType != Duration {
+			continue
+		}
+
+		cd, err := parseCalendarDuration(input[v+1], fp.Locale)
+		if err != nil {
+			return nil, err
+		}
+		fp.durations[input[v]] = cd
+		input[v+1] = cd.String()
+	}
+	return input, nil
+}
@@ -0,0 +1,167 @@
+package flagParser
+
+import (
+	"testing"
+	"time"
+)
+
+func _getDurationTestFlags() []FlagInfo {
+	var ret []FlagInfo
+
+	f1 := FlagInfo{FlagName: "-b", FlagType: Str, MaxLen: 2000}
+	f2 := FlagInfo{FlagName: "-t", FlagType: Duration, MaxLen: 30}
+	f3 := FlagInfo{FlagName: "-s", FlagType: Duration, MaxLen: 30}
+
+	ret = append(ret, f1, f2, f3)
+	return ret
+}
+
+func _getDurationTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-t", "P1DT2H"},
+		expected:    []string{"-t", "P1DT2H"},
+		name:        "iso",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "p2wt90m"},
+		expected:    []string{"-t", "P14DT1H30M"},
+		name:        "iso lower case with weeks",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "PT0,5S"},
+		expected:    []string{"-t", "PT0.5S"},
+		name:        "iso with fraction",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "1h30m"},
+		expected:    []string{"-t", "PT1H30M"},
+		name:        "go style",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "2m30s"},
+		expected:    []string{"-t", "PT2M30S"},
+		name:        "go style minutes & seconds",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "1.5h"},
+		expected:    []string{"-t", "PT1H30M"},
+		name:        "go style fractional hours",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "2.5m"},
+		expected:    []string{"-t", "PT2M30S"},
+		name:        "fraction makes m minutes",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "2h"},
+		expected:    []string{"-t", "PT2H"},
+		name:        "lone hours",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "36h"},
+		expected:    []string{"-t", "PT36H"},
+		name:        "hours aren't folded into days",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "3d2m"},
+		expected:    []string{"-t", "P2M3D"},
+		name:        "shorthand m is months",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "30m"},
+		expected:    []string{"-t", "P30M"},
+		name:        "bare m is months",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "1q", "1w", "45min"},
+		expected:    []string{"-t", "P3M7DT45M"},
+		name:        "shorthand with spaces",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "0d"},
+		expected:    []string{"-t", "PT0S"},
+		name:        "zero",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"snooze", "-t", "-2h", "-s", "PT5M"},
+		expected:    []string{"-t", "-PT2H", "-s", "PT5M", "-b", "snooze"},
+		name:        "two duration flags",
+		systemFlags: _getDurationTestFlags,
+	}, {
+		args:        []string{"-t", "3b"},
+		expected:    []string{},
+		name:        "business days",
+		systemFlags: _getDurationTestFlags,
+		err:         &InvalidDurationError{},
+	}, {
+		args:        []string{"-t", "1d-2h"},
+		expected:    []string{},
+		name:        "mixed signs",
+		systemFlags: _getDurationTestFlags,
+		err:         &InvalidDurationError{},
+	}, {
+		args:        []string{"-t", "P1DT"},
+		expected:    []string{},
+		name:        "iso ending in T",
+		systemFlags: _getDurationTestFlags,
+		err:         &InvalidDurationError{},
+	}, {
+		args:        []string{"-t", "tomorrow"},
+		expected:    []string{},
+		name:        "not a duration",
+		systemFlags: _getDurationTestFlags,
+		err:         &InvalidDurationError{},
+	}}
+}
+
+func TestDurationParsing(t *testing.T) {
+	tcs := _getDurationTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestTypedDuration(t *testing.T) {
+	fp := NewFlagParser(_getDurationTestFlags(), []string{"-t", "1h30m", "-s", "P1M"}, WithNowAs(returnNowString(), "2006-01-02"))
+	_, err := fp.ParseUserInput()
+	if err != nil {
+		t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+		return
+	}
+
+	exact, _ := fp.GetDuration("-t")
+	if d, ok := exact.Exact(); !ok || d != 90*time.Minute {
+		t.Errorf(">>>>FAILED: exact duration. \nGot\t'%v' '%v'", d, ok)
+	}
+
+	cal, _ := fp.GetDuration("-s")
+	if _, ok := cal.Exact(); ok || !cal.IsCalendar() {
+		t.Errorf(">>>>FAILED: month should be a calendar offset")
+	}
+	feb := time.Date(2022, 02, 01, 9, 0, 0, 0, time.UTC)
+	if got := cal.AddTo(feb.AddDate(0, -1, 0)); !got.Equal(feb) {
+		t.Errorf(">>>>FAILED: calendar offset. \nExp\t'%v' \nGot\t'%v'", feb, got)
+	}
+	if neg, err := ParseCalendarDuration("-P1W"); err != nil || neg.String() != "-P7D" {
+		t.Errorf(">>>>FAILED: negative iso duration. \nGot\t'%v' '%v'", neg, err)
+	}
+
+	back, err := ParseCalendarDuration(cal.String())
+	if err != nil || back != cal {
+		t.Errorf(">>>>FAILED: duration doesn't round trip. \nExp\t'%v' \nGot\t'%v'", cal, back)
+	}
+}
+
+func TestGoDurationDetection(t *testing.T) {
+	exp := map[string]bool{"1.5h": true, "2h": true, "1h30m": true, "500ms": true, "30m": false, "3d2h": false, "2h3d": false}
+	for input, goStyle := range exp {
+		if got := isGoDuration(input); got != goStyle {
+			t.Errorf(">>>>FAILED: '%v'. \nExp\t'%v' \nGot\t'%v'", input, goStyle, got)
+		}
+	}
+	if cd, err := ParseCalendarDuration("1.5h"); err != nil || cd.Clock != 90*time.Minute {
+		t.Errorf(">>>>FAILED: '1.5h'. \nGot\t'%v' '%v'", cd, err)
+	}
+}
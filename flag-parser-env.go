@@ -0,0 +1,18 @@
+package flagParser
+
+import (
+	"os"
+	"strings"
+)
+
+// Looks variables up with LookupEnv, or os.LookupEnv if it's nil.
+// Empty variables count as unset.
+func (fp *FlagParser) lookupEnv(name string) (string, bool) {
+	lookup := fp.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	v, ok := lookup(name)
+	v = strings.TrimSpace(v)
+	return v, ok && v != ""
+}
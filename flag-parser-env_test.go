@@ -0,0 +1,170 @@
+package flagParser
+
+import (
+	"errors"
+	"testing"
+)
+
+func _getEnvTestFlags() []FlagInfo {
+	var ret []FlagInfo
+
+	f1 := FlagInfo{FlagName: "-b", FlagType: Str, MaxLen: 200, EnvVar: "TODO_BODY"}
+	f2 := FlagInfo{FlagName: "-t", FlagType: Str, MaxLen: 10, EnvVar: "TODO_TAG"}
+	f3 := FlagInfo{FlagName: "-m", FlagType: Integer, MaxLen: 3, EnvVar: "TODO_MAX"}
+	f4 := FlagInfo{FlagName: "-d", FlagType: DateTime, MaxLen: 40, AllowDateRange: true, EnvVar: "TODO_DUE"}
+	f5 := FlagInfo{FlagName: "-u", FlagType: Boolean, Standalone: true, EnvVar: "TODO_URGENT"}
+	f6 := FlagInfo{FlagName: "-p", FlagType: Duration, MaxLen: 20, EnvVar: "TODO_PERIOD"}
+
+	ret = append(ret, f1, f2, f3, f4, f5, f6)
+	return ret
+}
+
+func _withEnv(env map[string]string) func(*FlagParser) {
+	return func(fp *FlagParser) {
+		fp.LookupEnv = func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		}
+	}
+}
+
+func _getEnvTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-b", "buy milk"},
+		expected:    []string{"-b", "buy milk", "-t", "shopping"},
+		name:        "env fills missing flag",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_TAG": "shopping"}),
+	}, {
+		args:        []string{"-b", "buy milk", "-t", "home"},
+		expected:    []string{"-b", "buy milk", "-t", "home"},
+		name:        "argv beats env",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_TAG": "shopping"}),
+	}, {
+		args:        []string{"buy", "milk"},
+		expected:    []string{"-b", "buy milk"},
+		name:        "implicit text beats env",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_BODY": "from env"}),
+	}, {
+		args:        []string{"milk"},
+		expected:    []string{"-b", "milk"},
+		name:        "single arg parsed without env",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(nil),
+	}, {
+		args:        []string{"milk"},
+		expected:    []string{"-b", "milk", "-m", "5"},
+		name:        "single arg still parsed with env",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_MAX": "5"}),
+	}, {
+		args:        []string{},
+		expected:    []string{"-b", "body"},
+		name:        "env fills implicit flag without args",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_BODY": "body"}),
+	}, {
+		args:        []string{},
+		expected:    []string{"-t", "work"},
+		name:        "no args & no implicit env",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_TAG": "work"}),
+	}, {
+		args:        []string{"-u"},
+		expected:    []string{"-u", "-b", "body"},
+		name:        "env fills implicit flag after standalone",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_BODY": "body"}),
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{"-b", "x", "-d", "2022-03-17:2022-03-21"},
+		name:        "env dates resolved",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_DUE": "3d:1w"}),
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{"-b", "x", "-p", "PT1H30M"},
+		name:        "env durations normalised",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_PERIOD": "1h30m"}),
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{"-b", "x", "-u"},
+		name:        "env standalone on",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_URGENT": "true", "TODO_TAG": " "}),
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{"-b", "x"},
+		name:        "env standalone off",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_URGENT": "0"}),
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{},
+		name:        "env standalone not boolean",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_URGENT": "yes please"}),
+		err:         &EnvValueError{},
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{},
+		name:        "env value too long",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_TAG": "much too long for a tag"}),
+		err:         &EnvValueError{},
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{},
+		name:        "env number with leftovers",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_MAX": "12 items"}),
+		err:         &EnvValueError{},
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{},
+		name:        "env date invalid",
+		systemFlags: _getEnvTestFlags,
+		configure:   _withEnv(map[string]string{"TODO_DUE": "banana"}),
+		err:         &EnvValueError{},
+	}}
+}
+
+func TestEnvFallback(t *testing.T) {
+	tcs := _getEnvTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestEnvValueErrorDetail(t *testing.T) {
+	fp := NewFlagParser(_getEnvTestFlags(), []string{"-b", "x"}, WithNowAs(returnNowString(), "2006-01-02"))
+	_withEnv(map[string]string{"TODO_DUE": "banana"})(fp)
+
+	_, err := fp.ParseUserInput()
+	var ee *EnvValueError
+	if !errors.As(err, &ee) || ee.Var != "TODO_DUE" || ee.Flag != "-d" {
+		t.Errorf(">>>>FAILED: expected env error for TODO_DUE. \nGot\t'%v'", err)
+	}
+	var le *InvalidDateLiteralError
+	if !errors.As(err, &le) {
+		t.Errorf(">>>>FAILED: expected wrapped date error. \nGot\t'%v'", err)
+	}
+
+	t.Setenv("FLAG_PARSER_TEST_TAG", "work")
+	flags := _getEnvTestFlags()
+	flags[1].EnvVar = "FLAG_PARSER_TEST_TAG"
+	got, err := NewFlagParser(flags, []string{"-b", "x"}, WithNowAs(returnNowString(), "2006-01-02")).ParseUserInput()
+	if err != nil || !_slicesAreTheSame(got, []string{"-b", "x", "-t", "work"}) {
+		t.Errorf(">>>>FAILED: os environment. \nGot\t'%v' '%v'", got, err)
+	}
+
+	flags[2].EnvVar = "BAD=NAME"
+	if !_errorsMatch(ValidateFlags(flags), &InvalidFlagSpecError{}) {
+		t.Errorf(">>>>FAILED: expected invalid env var name")
+	}
+}
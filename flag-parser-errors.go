@@ -1,5 +1,11 @@
 package flagParser
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type UserArgsContainsUnknownFlag struct{}
 
 func (u *UserArgsContainsUnknownFlag) Error() string {
@@ -12,9 +18,15 @@ func (e *ExceedMaxLengthError) Error() string {
 	return "maximum argument length exceeded"
 }
 
-type UnknownDateInputError struct{}
+type UnknownDateInputError struct {
+	Input  string
+	Reason string
+}
 
 func (u *UnknownDateInputError) Error() string {
+	if u.Reason != "" {
+		return fmt.Sprintf("unknown elements in date argument '%v': %v", u.Input, u.Reason)
+	}
 	return "unknown elements in date argument"
 }
 
@@ -30,14 +42,235 @@ func (m *MissingArgumentError) Error() string {
 	return "flag missing argument"
 }
 
-type MalformedDateRangeError struct{}
+type MalformedDateRangeError struct {
+	Err error
+}
 
 func (m *MalformedDateRangeError) Error() string {
+	if m.Err != nil {
+		return "malformed date range provided: " + m.Err.Error()
+	}
 	return "malformed date range provided"
 }
 
+func (m *MalformedDateRangeError) Unwrap() error {
+	return m.Err
+}
+
 type DateRangeNotAllowedError struct{}
 
 func (d *DateRangeNotAllowedError) Error() string {
 	return "date range not allowed with flag provided"
 }
+
+// Describes a single problem found in a canonical []FlagInfo
+type FlagSpecIssue struct {
+	Index    int
+	FlagName string
+	Reason   string
+}
+
+type InvalidFlagSpecError struct {
+	Issues []FlagSpecIssue
+}
+
+func (i *InvalidFlagSpecError) Error() string {
+	var msgs []string
+	for _, v := range i.Issues {
+		msgs = append(msgs, fmt.Sprintf("'%v' (index %v): %v", v.FlagName, v.Index, v.Reason))
+	}
+	return "invalid flag spec: " + strings.Join(msgs, "; ")
+}
+
+type NowMomentParseError struct {
+	Input  string
+	Layout string
+	Err    error
+}
+
+func (n *NowMomentParseError) Error() string {
+	return fmt.Sprintf("unable to parse now moment '%v' with layout '%v': %v", n.Input, n.Layout, n.Err)
+}
+
+func (n *NowMomentParseError) Unwrap() error {
+	return n.Err
+}
+
+type InvalidDateLiteralError struct {
+	Input   string
+	Layouts []string
+}
+
+func (i *InvalidDateLiteralError) Error() string {
+	return fmt.Sprintf("invalid date '%v'; expected format %v", i.Input, strings.Join(i.Layouts, " or "))
+}
+
+type ReversedDateRangeError struct {
+	Start string
+	End   string
+}
+
+func (r *ReversedDateRangeError) Error() string {
+	return fmt.Sprintf("date range start '%v' is after end '%v'", r.Start, r.End)
+}
+
+type DateRangeSpanError struct {
+	MaxSpan string
+}
+
+func (d *DateRangeSpanError) Error() string {
+	return fmt.Sprintf("date range exceeds maximum span of '%v'", d.MaxSpan)
+}
+
+type MonthOverflowError struct {
+	Base   time.Time
+	Years  int
+	Months int
+}
+
+func (m *MonthOverflowError) Error() string {
+	return fmt.Sprintf("adding %vy%vm to %v overflows the end of the month", m.Years, m.Months, StringFromDate(m.Base))
+}
+
+type UnknownTimeZoneError struct {
+	Zone string
+}
+
+func (u *UnknownTimeZoneError) Error() string {
+	return fmt.Sprintf("unknown time zone '%v'", u.Zone)
+}
+
+type InvalidRecurrenceError struct {
+	Input  string
+	Reason string
+}
+
+func (i *InvalidRecurrenceError) Error() string {
+	return fmt.Sprintf("invalid recurrence '%v': %v", i.Input, i.Reason)
+}
+
+type InvalidDurationError struct {
+	Input  string
+	Reason string
+}
+
+func (i *InvalidDurationError) Error() string {
+	return fmt.Sprintf("invalid duration '%v': %v", i.Input, i.Reason)
+}
+
+type AmbiguousDateError struct {
+	Input  string
+	Orders []DateOrder
+	Dates  []string
+}
+
+func (a *AmbiguousDateError) Error() string {
+	var opts []string
+	for i := range a.Dates {
+		opts = append(opts, fmt.Sprintf("%v (%v)", a.Dates[i], a.Orders[i]))
+	}
+	return fmt.Sprintf("ambiguous date '%v': could be %v", a.Input, strings.Join(opts, " or "))
+}
+
+type InvalidCalendarDateError struct {
+	Input     string
+	Component string //'day', 'month', 'hour' etc.
+}
+
+func (i *InvalidCalendarDateError) Error() string {
+	return fmt.Sprintf("invalid date '%v': %v out of range", i.Input, i.Component)
+}
+
+type RangeFormatMismatchError struct {
+	Start string
+	End   string
+}
+
+func (r *RangeFormatMismatchError) Error() string {
+	return fmt.Sprintf("'%v' & '%v' differ in format; use two dates or two timestamps", r.Start, r.End)
+}
+
+type HolidayFileError struct {
+	Path   string
+	Line   int
+	Reason string
+}
+
+func (h *HolidayFileError) Error() string {
+	msg := "invalid holiday file"
+	if h.Path != "" {
+		msg += fmt.Sprintf(" '%v'", h.Path)
+	}
+	if h.Line > 0 {
+		msg += fmt.Sprintf(" at line %v", h.Line)
+	}
+	return msg + ": " + h.Reason
+}
+
+type EnvValueError struct {
+	Var  string
+	Flag string
+	Err  error
+}
+
+func (e *EnvValueError) Error() string {
+	return fmt.Sprintf("invalid value in environment variable '%v' for flag '%v': %v", e.Var, e.Flag, e.Err)
+}
+
+func (e *EnvValueError) Unwrap() error {
+	return e.Err
+}
+
+type ConfigFileError struct {
+	Path   string
+	Line   int
+	Reason string
+}
+
+func (c *ConfigFileError) Error() string {
+	msg := "invalid config file"
+	if c.Path != "" {
+		msg += fmt.Sprintf(" '%v'", c.Path)
+	}
+	if c.Line > 0 {
+		msg += fmt.Sprintf(" at line %v", c.Line)
+	}
+	return msg + ": " + c.Reason
+}
+
+type ConfigValueError struct {
+	Path string
+	Line int
+	Key  string
+	Flag string
+	Err  error
+}
+
+func (c *ConfigValueError) Error() string {
+	return fmt.Sprintf("invalid value for '%v' (flag '%v') in '%v' at line %v: %v", c.Key, c.Flag, c.Path, c.Line, c.Err)
+}
+
+func (c *ConfigValueError) Unwrap() error {
+	return c.Err
+}
+
+type DefaultValueError struct {
+	Flag string
+	Err  error
+}
+
+func (d *DefaultValueError) Error() string {
+	return fmt.Sprintf("invalid default for flag '%v': %v", d.Flag, d.Err)
+}
+
+func (d *DefaultValueError) Unwrap() error {
+	return d.Err
+}
+
+type AmbiguousRangeSeparatorError struct {
+	Input string
+}
+
+func (a *AmbiguousRangeSeparatorError) Error() string {
+	return fmt.Sprintf("can't tell which ':' separates the range in '%v' from times of day; separate timestamps with '..' or a RangeSeparator without ':'", a.Input)
+}
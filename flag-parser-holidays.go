@@ -0,0 +1,187 @@
+package flagParser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Consecutive days off after which business day offsets give up;
+// stops a calendar marking every day as a holiday from looping forever
+const maxHolidayRun = 366
+
+// Days skipped by business day offsets ('3b') on top of weekends
+type HolidayCalendar interface {
+	IsHoliday(d time.Time) bool
+}
+
+// Holidays by date ('2022-12-25'), with their names where known
+type HolidayList map[string]string
+
+// Checks d's date in its own location
+func (h HolidayList) IsHoliday(d time.Time) bool {
+	_, ok := h[d.Format(defaultDateLayout)]
+	return ok
+}
+
+// Flag calendar takes precedence over the parser's
+func (fp *FlagParser) holidaysFor(fi flag_info_key) HolidayCalendar {
+	if fi.holidays != nil {
+		return fi.holidays
+	}
+	return fp.Holidays
+}
+
+// Reads a newline-separated list of dates, each optionally followed
+// by a name ('2022-12-25 Christmas Day'). Blank lines & lines
+// starting with '#' are skipped.
+func ParseHolidayList(r io.Reader) (HolidayList, error) {
+	ret := make(HolidayList)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(text, " ", 2)
+		d, err := time.Parse(defaultDateLayout, fields[0])
+		if err != nil {
+			return nil, &HolidayFileError{Line: line, Reason: "expected a date like 2022-12-25"}
+		}
+		name := ""
+		if len(fields) == 2 {
+			name = strings.TrimSpace(fields[1])
+		}
+		ret[StringFromDate(d)] = name
+	}
+	if err := sc.Err(); err != nil {
+		return nil, &HolidayFileError{Reason: err.Error()}
+	}
+	return ret, nil
+}
+
+// Reads the all-day events of an iCalendar (RFC 5545) file. Events
+// with a time of day are skipped; an all-day event without DTEND
+// lasts one day. Recurring events are rejected rather than expanded.
+func ParseICSHolidays(r io.Reader) (HolidayList, error) {
+	ret := make(HolidayList)
+	var inEvent, timed bool
+	var start, end, name, rrule string
+	eventLine := 0
+
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, &HolidayFileError{Reason: err.Error()}
+	}
+
+	for _, l := range lines {
+		prop, value := splitICSProperty(l.text)
+		switch {
+		case prop == "BEGIN" && value == "VEVENT":
+			inEvent, timed, start, end, name, rrule, eventLine = true, false, "", "", "", "", l.num
+		case !inEvent:
+		case prop == "DTSTART":
+			start, timed = value, strings.Contains(value, "T")
+		case prop == "DTEND":
+			end = value
+		case prop == "SUMMARY":
+			name = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\\`, `\`).Replace(value)
+		case prop == "RRULE":
+			rrule = value
+		case prop == "END" && value == "VEVENT":
+			inEvent = false
+			if timed {
+				continue
+			}
+			if rrule != "" {
+				return nil, &HolidayFileError{Line: eventLine, Reason: "recurring events aren't supported"}
+			}
+			if err := addICSEvent(ret, start, end, name); err != nil {
+				return nil, &HolidayFileError{Line: eventLine, Reason: err.Error()}
+			}
+		}
+	}
+	return ret, nil
+}
+
+// Opens path & reads it with ParseHolidayList
+func LoadHolidayList(path string) (HolidayList, error) {
+	return loadHolidays(path, ParseHolidayList)
+}
+
+// Opens path & reads it with ParseICSHolidays
+func LoadICSHolidays(path string) (HolidayList, error) {
+	return loadHolidays(path, ParseICSHolidays)
+}
+
+func loadHolidays(path string, parse func(io.Reader) (HolidayList, error)) (HolidayList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &HolidayFileError{Path: path, Reason: err.Error()}
+	}
+	defer f.Close()
+
+	ret, err := parse(f)
+	if hfe, ok := err.(*HolidayFileError); ok {
+		hfe.Path = path
+	}
+	return ret, err
+}
+
+// DTEND is exclusive, so a one day event ends the next day
+func addICSEvent(hl HolidayList, start, end, name string) error {
+	s, err := time.Parse("20060102", start)
+	if err != nil {
+		return fmt.Errorf("DTSTART '%v' must be a date like 20221225", start)
+	}
+	e := s.AddDate(0, 0, 1)
+	if end != "" {
+		e, err = time.Parse("20060102", end)
+		if err != nil || !e.After(s) {
+			return fmt.Errorf("DTEND '%v' must be a date after DTSTART", end)
+		}
+	}
+
+	for d := s; d.Before(e); d = d.AddDate(0, 0, 1) {
+		hl[StringFromDate(d)] = name
+	}
+	return nil
+}
+
+type icsLine struct {
+	text string
+	num  int
+}
+
+// Joins folded lines (those starting with a space or tab) onto the
+// line before, keeping the number of the first for errors
+func unfoldICS(r io.Reader) ([]icsLine, error) {
+	var ret []icsLine
+	sc := bufio.NewScanner(r)
+	for num := 1; sc.Scan(); num++ {
+		text := strings.TrimRight(sc.Text(), "\r")
+		if len(ret) > 0 && (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) {
+			ret[len(ret)-1].text += text[1:]
+			continue
+		}
+		ret = append(ret, icsLine{text: text, num: num})
+	}
+	return ret, sc.Err()
+}
+
+// 'DTSTART;VALUE=DATE:20221225' -> 'DTSTART', '20221225'
+func splitICSProperty(line string) (prop, value string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), ""
+	}
+	prop = line[:colon]
+	if semi := strings.Index(prop, ";"); semi >= 0 {
+		prop = prop[:semi]
+	}
+	return strings.ToUpper(prop), strings.TrimSpace(line[colon+1:])
+}
@@ -0,0 +1,196 @@
+package flagParser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const _testICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20220317\r\n" +
+	"SUMMARY:St Patrick\\'s\r\n" +
+	" Day\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20220321\r\n" +
+	"DTEND;VALUE=DATE:20220323\r\n" +
+	"SUMMARY:Two day break\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20220315T090000Z\r\n" +
+	"SUMMARY:Meeting\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func _writeTestFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestICSHolidays(t *testing.T) {
+	hl, err := LoadICSHolidays(_writeTestFile(t, "hols.ics", _testICS))
+	if err != nil {
+		t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+		return
+	}
+
+	exp := []string{"2022-03-17", "2022-03-21", "2022-03-22"}
+	if len(hl) != len(exp) {
+		t.Errorf(">>>>FAILED: wrong holidays. \nExp\t'%v' \nGot\t'%v'", exp, hl)
+	}
+	for _, d := range exp {
+		if _, ok := hl[d]; !ok {
+			t.Errorf(">>>>FAILED: missing holiday '%v'", d)
+		}
+	}
+	if !strings.HasSuffix(hl["2022-03-17"], "Day") {
+		t.Errorf(">>>>FAILED: folded summary. \nGot\t'%v'", hl["2022-03-17"])
+	}
+}
+
+func TestICSHolidayErrors(t *testing.T) {
+	cases := map[string]string{
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20221225\nRRULE:FREQ=YEARLY\nEND:VEVENT\n":         "recurring",
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20221225\nDTEND;VALUE=DATE:20221224\nEND:VEVENT\n": "DTEND",
+		"BEGIN:VEVENT\nSUMMARY:No start\nEND:VEVENT\n":                                       "DTSTART",
+	}
+	for content, want := range cases {
+		_, err := ParseICSHolidays(strings.NewReader(content))
+		hfe, ok := err.(*HolidayFileError)
+		if !ok || hfe.Line != 1 || !strings.Contains(hfe.Reason, want) {
+			t.Errorf(">>>>FAILED: expected error about '%v'. \nGot\t'%v'", want, err)
+		}
+	}
+
+	if _, err := LoadICSHolidays(filepath.Join(t.TempDir(), "missing.ics")); !_errorsMatch(err, &HolidayFileError{}) {
+		t.Errorf(">>>>FAILED: missing file. \nGot\t'%v'", err)
+	}
+}
+
+func TestHolidayList(t *testing.T) {
+	hl, err := ParseHolidayList(strings.NewReader("# Irish holidays\n2022-03-17 St Patrick's Day\n\n2022-04-18\n"))
+	if err != nil || len(hl) != 2 || hl["2022-03-17"] != "St Patrick's Day" || !hl.IsHoliday(time.Date(2022, 04, 18, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf(">>>>FAILED: date list. \nGot\t'%v' '%v'", hl, err)
+	}
+
+	path := _writeTestFile(t, "hols.txt", "2022-03-17\n17/03/2022\n")
+	_, err = LoadHolidayList(path)
+	if hfe, ok := err.(*HolidayFileError); !ok || hfe.Line != 2 || hfe.Path != path {
+		t.Errorf(">>>>FAILED: bad line. \nGot\t'%v'", err)
+	}
+}
+
+func _getHolidayTestCases() []parsing_test_case {
+	hols := HolidayList{"2022-03-17": "", "2022-03-18": ""}
+	withHols := func(fp *FlagParser) { fp.Holidays = hols }
+
+	return []parsing_test_case{{
+		args:        []string{"-d", "3b"},
+		expected:    []string{"-d", "2022-03-17"},
+		name:        "weekends only",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", "3b"},
+		expected:    []string{"-d", "2022-03-21"},
+		name:        "skips holidays",
+		systemFlags: _getRangeTestFlags,
+		configure:   withHols,
+	}, {
+		args:        []string{"-d", "2022-03-21-2b"},
+		expected:    []string{"-d", "2022-03-15"},
+		name:        "backwards over holidays",
+		systemFlags: _getRangeTestFlags,
+		configure:   withHols,
+	}, {
+		args:        []string{"-d", "2022-03-16:+1b"},
+		expected:    []string{"-d", "2022-03-16:2022-03-21"},
+		name:        "anchored range end",
+		systemFlags: _getRangeTestFlags,
+		configure:   withHols,
+	}, {
+		args:     []string{"-d", "3b"},
+		expected: []string{"-d", "2022-03-17"},
+		name:     "flag calendar overrides parser's",
+		systemFlags: func() []FlagInfo {
+			flags := _getRangeTestFlags()
+			flags[1].Holidays = HolidayList{} //-d
+			return flags
+		},
+		configure: withHols,
+	}}
+}
+
+func TestBusinessDayHolidays(t *testing.T) {
+	tcs := _getHolidayTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+type _everyDayOff struct{}
+
+func (_everyDayOff) IsHoliday(time.Time) bool { return true }
+
+func TestNoBusinessDays(t *testing.T) {
+	fp := NewFlagParser(_getRangeTestFlags(), []string{"-d", "1b"}, WithNowAs(returnNowString(), "2006-01-02"))
+	fp.Holidays = _everyDayOff{}
+	if _, err := fp.ParseUserInput(); !_errorsMatch(err, &UnknownDateInputError{}) {
+		t.Errorf(">>>>FAILED: expected error for calendar without business days. \nGot\t'%v'", err)
+	}
+}
+
+// Steps a day at a time, as addBusinessDays did before skipping weeks
+func _addBusinessDaysByDay(d time.Time, n int, cal HolidayCalendar) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		d = d.AddDate(0, 0, step)
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday && (cal == nil || !cal.IsHoliday(d)) {
+			n--
+		}
+	}
+	return d
+}
+
+type _firstOfMonthOff struct{}
+
+func (_firstOfMonthOff) IsHoliday(d time.Time) bool { return d.Day() == 1 }
+
+func TestBusinessDayWeekSkipping(t *testing.T) {
+	hols := HolidayList{"2022-03-17": "", "2022-03-18": "", "2022-04-15": "", "2022-04-16": "", "2021-12-27": "", "2023-01-02": ""}
+	cals := map[string]HolidayCalendar{"none": nil, "list": hols, "custom": _firstOfMonthOff{}}
+	starts := []time.Time{
+		time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC),  //Monday
+		time.Date(2022, 3, 19, 9, 30, 0, 0, time.UTC), //Saturday
+	}
+
+	for name, cal := range cals {
+		for _, start := range starts {
+			for _, n := range []int{1, 4, 5, 6, 11, 23, 260, -1, -5, -6, -19, -260} {
+				exp := _addBusinessDaysByDay(start, n, cal)
+				got, err := addBusinessDays(start, n, cal)
+				if err != nil || !got.Equal(exp) {
+					t.Errorf(">>>>FAILED: %v, %v, %vb. \nExp\t'%v' \nGot\t'%v' '%v'", name, start, n, exp, got, err)
+				}
+			}
+		}
+	}
+
+	if _, err := addBusinessDays(starts[0], 1000000, hols); err != nil {
+		t.Errorf(">>>>FAILED: large offset. \nGot\t'%v'", err)
+	}
+	if _, err := addBusinessDays(starts[0], 1000000, _everyDayOff{}); !_errorsMatch(err, &UnknownDateInputError{}) {
+		t.Errorf(">>>>FAILED: expected error for calendar without business days. \nGot\t'%v'", err)
+	}
+}
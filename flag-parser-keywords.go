@@ -0,0 +1,107 @@
+package flagParser
+
+import (
+	"strings"
+	"time"
+)
+
+// Resolves a date keyword relative to now
+type keywordFunc func(now time.Time) time.Time
+
+// Keywords usable in place of a date. Weeks start on Monday (ISO 8601).
+// Input has had spaces removed, so 'end of month' arrives as 'endofmonth'.
+var dateKeywords = map[string]keywordFunc{
+	"now":       func(n time.Time) time.Time { return n },
+	"today":     func(n time.Time) time.Time { return n },
+	"tomorrow":  func(n time.Time) time.Time { return n.AddDate(0, 0, 1) },
+	"yesterday": func(n time.Time) time.Time { return n.AddDate(0, 0, -1) },
+
+	"sow": startOfWeek, "startofweek": startOfWeek,
+	"eow": endOfWeek, "endofweek": endOfWeek,
+	"som": startOfMonth, "startofmonth": startOfMonth,
+	"eom": endOfMonth, "endofmonth": endOfMonth,
+	"soq": startOfQuarter, "startofquarter": startOfQuarter,
+	"eoq": endOfQuarter, "endofquarter": endOfQuarter,
+	"soy": startOfYear, "startofyear": startOfYear,
+	"eoy": endOfYear, "endofyear": endOfYear,
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+	"sunday": time.Sunday, "sun": time.Sunday,
+}
+
+// Checks input (lower case, no spaces) for a date keyword. Weekday
+// names resolve to the next occurrence, today included. 'next' skips
+// today & 'last' looks back from yesterday. The direction can also
+// follow the weekday, as in translated input ('lundi prochain').
+func resolveKeyword(input string, now time.Time) (time.Time, bool) {
+	if kf, ok := dateKeywords[input]; ok {
+		return kf(now), true
+	}
+
+	dir, name := 0, input
+	for prefix, d := range map[string]int{"next": 1, "last": -1, "this": 0} {
+		if strings.HasPrefix(input, prefix) {
+			dir, name = d, strings.TrimPrefix(input, prefix)
+			break
+		}
+		if strings.HasSuffix(input, prefix) {
+			dir, name = d, strings.TrimSuffix(input, prefix)
+			break
+		}
+	}
+
+	wd, ok := weekdayNames[name]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	diff := (int(wd) - int(now.Weekday()) + 7) % 7
+	switch {
+	case dir > 0 && diff == 0:
+		diff = 7
+	case dir < 0:
+		diff -= 7
+	}
+	return now.AddDate(0, 0, diff), true
+}
+
+func startOfWeek(n time.Time) time.Time {
+	back := (int(n.Weekday()) + 6) % 7 //days since Monday
+	return n.AddDate(0, 0, -back)
+}
+
+func endOfWeek(n time.Time) time.Time {
+	return startOfWeek(n).AddDate(0, 0, 6)
+}
+
+func startOfMonth(n time.Time) time.Time {
+	return n.AddDate(0, 0, 1-n.Day())
+}
+
+func endOfMonth(n time.Time) time.Time {
+	return startOfMonth(n).AddDate(0, 1, -1)
+}
+
+func startOfQuarter(n time.Time) time.Time {
+	som := startOfMonth(n)
+	return som.AddDate(0, -(int(n.Month())-1)%3, 0)
+}
+
+func endOfQuarter(n time.Time) time.Time {
+	return startOfQuarter(n).AddDate(0, 3, -1)
+}
+
+func startOfYear(n time.Time) time.Time {
+	return n.AddDate(0, 1-int(n.Month()), 1-n.Day())
+}
+
+func endOfYear(n time.Time) time.Time {
+	return startOfYear(n).AddDate(1, 0, -1)
+}
@@ -0,0 +1,107 @@
+package flagParser
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func _getDateKeywordTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "today"},
+		expected:    []string{"-d", "2022-03-14"},
+		name:        "today",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "yesterday"},
+		expected:    []string{"-d", "2022-03-13"},
+		name:        "yesterday",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "friday"},
+		expected:    []string{"-d", "2022-03-18"},
+		name:        "bare weekday",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "monday"},
+		expected:    []string{"-d", "2022-03-14"},
+		name:        "bare weekday is today",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "next", "mon"},
+		expected:    []string{"-d", "2022-03-21"},
+		name:        "next weekday skips today",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "last", "monday"},
+		expected:    []string{"-d", "2022-03-07"},
+		name:        "last weekday skips today",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "last", "fri"},
+		expected:    []string{"-d", "2022-03-11"},
+		name:        "last weekday",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "end", "of", "month"},
+		expected:    []string{"-d", "2022-03-31"},
+		name:        "end of month in words",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "sow:eow"},
+		expected:    []string{"-d", "2022-03-14:2022-03-20"},
+		name:        "week range",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "soq:eoq"},
+		expected:    []string{"-d", "2022-01-01:2022-03-31"},
+		name:        "quarter range",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "last fri:-2m"},
+		expected:    []string{"-d", "2022-03-11:2022-01-14"},
+		name:        "keyword on one side of range",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "friday", "9am"},
+		expected:    []string{"-d", "2022-03-18T09:00"},
+		name:        "weekday with clock literal",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+	}, {
+		args:        []string{"-d", "next", "someday"},
+		expected:    []string{},
+		name:        "unknown keyword",
+		systemFlags: _getCanonicalFlagsForGodoGettingTests,
+		err:         &InvalidDateLiteralError{},
+	}}
+}
+
+func TestDateKeywords(t *testing.T) {
+	os.Setenv("MAX_LENGTH", "2000")
+	os.Setenv("MAX_TAG_LENGTH", "10")
+	os.Setenv("MAX_INT_DIGITS", "4")
+
+	tcs := _getDateKeywordTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestPeriodBoundaryKeywords(t *testing.T) {
+	now := time.Date(2024, 02, 10, 0, 0, 0, 0, time.UTC) //Saturday in a leap year
+	exp := map[string]string{
+		"sow": "2024-02-05", "eow": "2024-02-11",
+		"som": "2024-02-01", "eom": "2024-02-29",
+		"soq": "2024-01-01", "eoq": "2024-03-31",
+		"soy": "2024-01-01", "eoy": "2024-12-31",
+	}
+
+	for kw, want := range exp {
+		got, ok := resolveKeyword(kw, now)
+		if !ok || StringFromDate(got) != want {
+			t.Errorf(">>>>FAILED: keyword '%v'. \nExp\t'%v' \nGot\t'%v'", kw, want, StringFromDate(got))
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package flagParser
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Maps one language's date words onto the English ones the parser
+// understands. English input is always accepted as well. Keys are
+// lower case; Keywords keys have no spaces & are matched against the
+// whole input first ('findumois'), then word by word. Keyword values
+// can be any English input, e.g. 'übermorgen' -> 'tomorrow+1d', or
+// empty to drop filler words. Month names become full English names,
+// so literal layouts need 'January' rather than 'Jan'.
+type Locale struct {
+	Name     string
+	Units    map[string]string //'j' -> 'd'; only applied straight after a number
+	Weekdays map[string]time.Weekday
+	Months   map[string]time.Month
+	Keywords map[string]string
+	// Field orders for numeric dates; see FlagParser.DateOrders
+	DateOrders []DateOrder
+}
+
+var localeWordPattern = regexp.MustCompile(`\pL+(?:'\pL+)?`) //keeps "aujourd'hui" whole
+
+// Built-in locales are copied on use, so changes to one parser's
+// locale don't reach others
+var english = Locale{Name: "en"}
+
+// English needs no translating. Its date order varies by
+// country, so none is assumed
+func English() *Locale { return english.Copy() }
+
+func French() *Locale { return french.Copy() }
+
+func German() *Locale { return german.Copy() }
+
+func Spanish() *Locale { return spanish.Copy() }
+
+var french = Locale{
+	Name:       "fr",
+	DateOrders: []DateOrder{DMY, YMD},
+	Units: map[string]string{
+		"a": "y", "an": "y", "ans": "y", "t": "q", "mois": "m", "s": "w", "sem": "w",
+		"j": "d", "jour": "d", "jours": "d", "jo": "b",
+	},
+	Weekdays: map[string]time.Weekday{
+		"lundi": time.Monday, "lun": time.Monday, "mardi": time.Tuesday,
+		"mercredi": time.Wednesday, "mer": time.Wednesday, "jeudi": time.Thursday, "jeu": time.Thursday,
+		"vendredi": time.Friday, "ven": time.Friday, "samedi": time.Saturday, "sam": time.Saturday,
+		"dimanche": time.Sunday, "dim": time.Sunday,
+	},
+	Months: map[string]time.Month{
+		"janvier": time.January, "janv": time.January, "février": time.February, "fevrier": time.February,
+		"févr": time.February, "mars": time.March, "avril": time.April, "avr": time.April, "mai": time.May,
+		"juin": time.June, "juillet": time.July, "juil": time.July, "août": time.August, "aout": time.August,
+		"septembre": time.September, "sept": time.September, "octobre": time.October, "oct": time.October,
+		"novembre": time.November, "nov": time.November, "décembre": time.December, "decembre": time.December,
+		"déc": time.December,
+	},
+	Keywords: map[string]string{
+		"maintenant": "now", "aujourd'hui": "today", "demain": "tomorrow", "hier": "yesterday",
+		"après-demain": "tomorrow+1d", "avant-hier": "yesterday-1d",
+		"prochain": "next", "prochaine": "next", "dernier": "last", "dernière": "last", "ce": "this", "cette": "this",
+		"le": "", "du": "from", "au": "to", "jusqu'au": "until", "depuis": "since", "entre": "between", "et": "and",
+		"débutdelasemaine": "startofweek", "findelasemaine": "endofweek", "débutdumois": "startofmonth",
+		"findumois": "endofmonth", "débutdutrimestre": "startofquarter", "findutrimestre": "endofquarter",
+		"débutdel'année": "startofyear", "findel'année": "endofyear",
+	},
+}
+
+var german = Locale{
+	Name:       "de",
+	DateOrders: []DateOrder{DMY, YMD},
+	Units: map[string]string{
+		"j": "y", "jahr": "y", "jahre": "y", "monat": "m", "monate": "m", "woche": "w", "wochen": "w",
+		"t": "d", "tag": "d", "tage": "d", "at": "b", "std": "h",
+	},
+	Weekdays: map[string]time.Weekday{
+		"montag": time.Monday, "mo": time.Monday, "dienstag": time.Tuesday, "di": time.Tuesday,
+		"mittwoch": time.Wednesday, "mi": time.Wednesday, "donnerstag": time.Thursday, "do": time.Thursday,
+		"freitag": time.Friday, "fr": time.Friday, "samstag": time.Saturday, "sonnabend": time.Saturday,
+		"sa": time.Saturday, "sonntag": time.Sunday, "so": time.Sunday,
+	},
+	Months: map[string]time.Month{
+		"januar": time.January, "jän": time.January, "februar": time.February, "märz": time.March,
+		"maerz": time.March, "mär": time.March, "april": time.April, "mai": time.May, "juni": time.June,
+		"juli": time.July, "august": time.August, "september": time.September, "oktober": time.October,
+		"okt": time.October, "november": time.November, "dezember": time.December, "dez": time.December,
+	},
+	Keywords: map[string]string{
+		"jetzt": "now", "heute": "today", "morgen": "tomorrow", "gestern": "yesterday",
+		"übermorgen": "tomorrow+1d", "vorgestern": "yesterday-1d",
+		"nächsten": "next", "nächster": "next", "nächste": "next", "naechsten": "next",
+		"letzten": "last", "letzter": "last", "letzte": "last", "diesen": "this", "dieser": "this", "diese": "this",
+		"am": "", "von": "from", "ab": "from", "bis": "until", "seit": "since", "zwischen": "between", "und": "and",
+		"wochenanfang": "startofweek", "monatsanfang": "startofmonth", "monatsende": "endofmonth",
+		"quartalsanfang": "startofquarter", "quartalsende": "endofquarter",
+		"jahresanfang": "startofyear", "jahresende": "endofyear",
+	},
+}
+
+var spanish = Locale{
+	Name:       "es",
+	DateOrders: []DateOrder{DMY, YMD},
+	Units: map[string]string{
+		"a": "y", "año": "y", "años": "y", "t": "q", "trimestre": "q", "mes": "m", "meses": "m",
+		"s": "w", "semana": "w", "semanas": "w", "día": "d", "días": "d", "dh": "b",
+	},
+	Weekdays: map[string]time.Weekday{
+		"lunes": time.Monday, "lun": time.Monday, "martes": time.Tuesday,
+		"miércoles": time.Wednesday, "miercoles": time.Wednesday, "mié": time.Wednesday,
+		"jueves": time.Thursday, "jue": time.Thursday, "viernes": time.Friday, "vie": time.Friday,
+		"sábado": time.Saturday, "sabado": time.Saturday, "sáb": time.Saturday,
+		"domingo": time.Sunday, "dom": time.Sunday,
+	},
+	Months: map[string]time.Month{
+		"enero": time.January, "ene": time.January, "febrero": time.February, "marzo": time.March,
+		"abril": time.April, "abr": time.April, "mayo": time.May, "junio": time.June, "julio": time.July,
+		"agosto": time.August, "ago": time.August, "septiembre": time.September, "setiembre": time.September,
+		"octubre": time.October, "noviembre": time.November, "diciembre": time.December, "dic": time.December,
+	},
+	Keywords: map[string]string{
+		"ahora": "now", "hoy": "today", "mañana": "tomorrow", "manana": "tomorrow", "ayer": "yesterday",
+		"pasadomañana": "tomorrow+1d", "anteayer": "yesterday-1d",
+		"próximo": "next", "proximo": "next", "próxima": "next", "siguiente": "next",
+		"pasado": "last", "pasada": "last", "este": "this", "esta": "this",
+		"el": "", "desde": "from", "hasta": "until", "entre": "between", "y": "and",
+		"iniciodemes": "startofmonth", "findemes": "endofmonth", "iniciodeaño": "startofyear", "findeaño": "endofyear",
+	},
+}
+
+// Deep copy, e.g. to extend a built-in locale
+func (loc *Locale) Copy() *Locale {
+	ret := &Locale{Name: loc.Name, DateOrders: append([]DateOrder(nil), loc.DateOrders...)}
+	if loc.Units != nil {
+		ret.Units = make(map[string]string)
+		for k, v := range loc.Units {
+			ret.Units[k] = v
+		}
+	}
+	if loc.Weekdays != nil {
+		ret.Weekdays = make(map[string]time.Weekday)
+		for k, v := range loc.Weekdays {
+			ret.Weekdays[k] = v
+		}
+	}
+	if loc.Months != nil {
+		ret.Months = make(map[string]time.Month)
+		for k, v := range loc.Months {
+			ret.Months[k] = v
+		}
+	}
+	if loc.Keywords != nil {
+		ret.Keywords = make(map[string]string)
+		for k, v := range loc.Keywords {
+			ret.Keywords[k] = v
+		}
+	}
+	return ret
+}
+
+// Rewrites loc's words in input as English. Units are only swapped
+// when withUnits is set & straight after a number, so 'a' in '3a' is
+// a year but any other 'a' is left alone. Callers leave units out
+// until literals have been tried, as '2022-03-14T09:00' would
+// otherwise have its 'T' taken for a unit. A nil locale leaves input as is.
+func (loc *Locale) translate(input string, withUnits bool) string {
+	if loc == nil {
+		return input
+	}
+	if kw, ok := loc.Keywords[strings.ToLower(strings.ReplaceAll(input, " ", ""))]; ok {
+		return kw
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range localeWordPattern.FindAllStringIndex(input, -1) {
+		word := input[m[0]:m[1]]
+		sb.WriteString(input[last:m[0]])
+		if followsNumber(input[:m[0]]) {
+			sb.WriteString(loc.translateUnit(word, withUnits))
+		} else {
+			sb.WriteString(loc.translateWord(word))
+		}
+		last = m[1]
+	}
+	sb.WriteString(input[last:])
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// Keywords are skipped after a number; Spanish 'y' is 'and' but '3y'
+// is still three years. Names are kept for literals like '14 mars'.
+func (loc *Locale) translateUnit(word string, withUnits bool) string {
+	lower := strings.ToLower(word)
+	if u, ok := loc.Units[lower]; ok {
+		if withUnits {
+			return u
+		}
+		return word
+	}
+	return loc.translateName(word)
+}
+
+func (loc *Locale) translateWord(word string) string {
+	if kw, ok := loc.Keywords[strings.ToLower(word)]; ok {
+		return kw
+	}
+	return loc.translateName(word)
+}
+
+func (loc *Locale) translateName(word string) string {
+	lower := strings.ToLower(word)
+	if wd, ok := loc.Weekdays[lower]; ok {
+		return strings.ToLower(wd.String())
+	}
+	if m, ok := loc.Months[lower]; ok {
+		return m.String()
+	}
+	return word //English or part of a literal
+}
+
+// True if the last non-space rune is a digit
+func followsNumber(before string) bool {
+	trimmed := strings.TrimRightFunc(before, unicode.IsSpace)
+	if trimmed == "" {
+		return false
+	}
+	runes := []rune(trimmed)
+	return unicode.IsDigit(runes[len(runes)-1])
+}
@@ -0,0 +1,188 @@
+package flagParser
+
+import (
+	"testing"
+	"time"
+)
+
+func _getLocaleTestFlags() []FlagInfo {
+	var ret []FlagInfo
+
+	f1 := FlagInfo{FlagName: "-b", FlagType: Str, MaxLen: 2000}
+	f2 := FlagInfo{FlagName: "-d", FlagType: DateTime, MaxLen: 40, AllowDateRange: true, AllowOpenRange: true}
+	f3 := FlagInfo{FlagName: "-t", FlagType: Duration, MaxLen: 20}
+
+	ret = append(ret, f1, f2, f3)
+	return ret
+}
+
+func _withLocale(loc *Locale) func(*FlagParser) {
+	return func(fp *FlagParser) {
+		fp.Locale = loc
+	}
+}
+
+func _getLocaleTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "3j"},
+		expected:    []string{"-d", "2022-03-17"},
+		name:        "french day unit",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(French()),
+	}, {
+		args:        []string{"-d", "2T"},
+		expected:    []string{"-d", "2022-03-16"},
+		name:        "german day unit",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(German()),
+	}, {
+		args:        []string{"-d", "1a2s"},
+		expected:    []string{"-d", "2023-03-28"},
+		name:        "spanish year & week units",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(Spanish()),
+	}, {
+		args:        []string{"-d", "3d1y"},
+		expected:    []string{"-d", "2023-03-17"},
+		name:        "english units still understood",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(Spanish()),
+	}, {
+		args:        []string{"-d", "demain"},
+		expected:    []string{"-d", "2022-03-15"},
+		name:        "french keyword",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(French()),
+	}, {
+		args:        []string{"-d", "übermorgen"},
+		expected:    []string{"-d", "2022-03-16"},
+		name:        "keyword mapped to expression",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(German()),
+	}, {
+		args:        []string{"-d", "fin", "du", "mois"},
+		expected:    []string{"-d", "2022-03-31"},
+		name:        "multi word keyword",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(French()),
+	}, {
+		args:        []string{"-d", "Monatsende+1T"},
+		expected:    []string{"-d", "2022-04-01"},
+		name:        "keyword with localised offset",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(German()),
+	}, {
+		args:        []string{"-d", "lundi prochain"},
+		expected:    []string{"-d", "2022-03-21"},
+		name:        "direction after weekday",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(French()),
+	}, {
+		args:        []string{"-d", "el viernes pasado"},
+		expected:    []string{"-d", "2022-03-11"},
+		name:        "filler word dropped",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(Spanish()),
+	}, {
+		args:        []string{"-d", "von heute bis 2T"},
+		expected:    []string{"-d", "2022-03-14:2022-03-16"},
+		name:        "word range",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(German()),
+	}, {
+		args:        []string{"-d", "14 mars 2022"},
+		expected:    []string{"-d", "2022-03-14"},
+		name:        "month name in literal",
+		systemFlags: _getLocaleTestFlags,
+		configure: func(fp *FlagParser) {
+			fp.Locale = French()
+			fp.DateInputLayouts = []string{"2 January 2006"}
+		},
+	}, {
+		args:        []string{"-d", "2022-03-20T09:00"},
+		expected:    []string{"-d", "2022-03-20T09:00"},
+		name:        "timestamp literal untouched",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(German()),
+	}, {
+		args:        []string{"-t", "2semanas"},
+		expected:    []string{"-t", "P14D"},
+		name:        "duration shorthand",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(Spanish()),
+	}, {
+		args:        []string{"-t", "2s"},
+		expected:    []string{"-t", "P14D"},
+		name:        "french weeks on a duration flag",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(French()),
+	}, {
+		args:        []string{"-d", "2s"},
+		expected:    []string{"-d", "2022-03-28"},
+		name:        "french weeks on a date flag",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(French()),
+	}, {
+		args:        []string{"-t", "PT2S"},
+		expected:    []string{"-t", "PT2S"},
+		name:        "french seconds through ISO 8601",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(French()),
+	}, {
+		args:        []string{"-t", "1.5h"},
+		expected:    []string{"-t", "PT1H30M"},
+		name:        "go duration with a locale",
+		systemFlags: _getLocaleTestFlags,
+		configure:   _withLocale(French()),
+	}, {
+		args:        []string{"-d", "3j"},
+		expected:    []string{},
+		name:        "no locale",
+		systemFlags: _getLocaleTestFlags,
+		err:         &InvalidDateLiteralError{},
+	}}
+}
+
+func TestLocales(t *testing.T) {
+	tcs := _getLocaleTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestCustomLocale(t *testing.T) {
+	dutch := &Locale{
+		Name:     "nl",
+		Units:    map[string]string{"dg": "d", "wk": "w"},
+		Weekdays: map[string]time.Weekday{"vrijdag": time.Friday},
+		Keywords: map[string]string{"morgen": "tomorrow", "volgende": "next"},
+	}
+
+	for in, want := range map[string]string{"2wk1dg": "2022-03-29", "morgen": "2022-03-15", "volgende vrijdag": "2022-03-18"} {
+		fp := NewFlagParser(_getLocaleTestFlags(), []string{"-d", in}, WithNowAs(returnNowString(), "2006-01-02"))
+		fp.Locale = dutch
+		got, err := fp.ParseUserInput()
+		if err != nil || got[1] != want {
+			t.Errorf(">>>>FAILED: custom locale. \nInp\t'%v' \nExp\t'%v' \nGot\t'%v' '%v'", in, want, got, err)
+		}
+	}
+}
+
+func TestLocaleCopies(t *testing.T) {
+	mine := French()
+	mine.Keywords["demain"] = "yesterday"
+	mine.DateOrders[0] = MDY
+
+	fresh := French()
+	if fresh.Keywords["demain"] != "tomorrow" || fresh.DateOrders[0] != DMY {
+		t.Errorf(">>>>FAILED: change to one copy reached another. \nGot\t'%v' '%v'", fresh.Keywords["demain"], fresh.DateOrders)
+	}
+
+	fp := NewFlagParser(_getLocaleTestFlags(), []string{"-d", "demain"}, WithNowAs(returnNowString(), "2006-01-02"))
+	fp.Locale = French()
+	if got, err := fp.ParseUserInput(); err != nil || got[1] != "2022-03-15" {
+		t.Errorf(">>>>FAILED: fresh locale. \nGot\t'%v' '%v'", got, err)
+	}
+}
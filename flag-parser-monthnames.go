@@ -0,0 +1,95 @@
+package flagParser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Which year a date without one ('14 mar') falls in
+type YearPreference int
+
+const (
+	PreferFuture YearPreference = iota //today or later
+	PreferPast                         //today or earlier
+)
+
+// Years searched for a date without a year; covers Feb 29
+const maxYearSearch = 8
+
+var monthNames = map[string]time.Month{
+	"january": time.January, "jan": time.January, "february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March, "april": time.April, "apr": time.April, "may": time.May,
+	"june": time.June, "jun": time.June, "july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August, "september": time.September, "sep": time.September,
+	"sept": time.September, "october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November, "december": time.December, "dec": time.December,
+}
+
+var (
+	monthDateTokenPattern = regexp.MustCompile(`\d+(?:st|nd|rd|th)?|\pL+`)
+	ordinalSuffixPattern  = regexp.MustCompile(`(?:st|nd|rd|th)$`)
+)
+
+// Reads a day & month name in either order, with an optional 4 digit
+// year anywhere ('14 mar', 'march 14 2023', '2023, the 14th of March').
+// Tokens can be run together ('14mar') or split by ' ,./-'.
+func (fp *FlagParser) parseMonthNameDate(input string, fi flag_info_key) (time.Time, bool, error) {
+	lower := strings.ToLower(strings.TrimSpace(input))
+	for _, r := range monthDateTokenPattern.ReplaceAllString(lower, "") {
+		if !strings.ContainsRune(" ,./-", r) {
+			return time.Time{}, false, nil
+		}
+	}
+
+	var month time.Month
+	day, year := 0, 0
+	for _, tok := range monthDateTokenPattern.FindAllString(lower, -1) {
+		num := ordinalSuffixPattern.ReplaceAllString(tok, "")
+		n, err := strconv.Atoi(num)
+
+		switch {
+		case tok == "the" || tok == "of":
+		case monthNames[tok] != 0 && month == 0:
+			month = monthNames[tok]
+		case err == nil && len(tok) == 4 && num == tok && year == 0:
+			year = n
+		case err == nil && len(num) <= 2 && day == 0:
+			day = n
+		default:
+			return time.Time{}, false, nil
+		}
+	}
+	if month == 0 || day == 0 {
+		return time.Time{}, false, nil
+	}
+
+	d, ok := time.Date(year, month, day, 0, 0, 0, 0, fp.locationFor(fi)), true
+	if year == 0 {
+		d, ok = inferYear(fp.nowFor(fi), month, day, fi.missingYear)
+	}
+	if !ok || d.Day() != day {
+		return time.Time{}, false, &InvalidCalendarDateError{Input: strings.TrimSpace(input), Component: "day"}
+	}
+	return d, true, nil
+}
+
+// The nearest year, in the preferred direction from now, in which
+// the date exists. Today counts either way.
+func inferYear(now time.Time, month time.Month, day int, pref YearPreference) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dir := 1
+	if pref == PreferPast {
+		dir = -1
+	}
+
+	for k := 0; k <= maxYearSearch; k++ {
+		d := time.Date(now.Year()+dir*k, month, day, 0, 0, 0, 0, now.Location())
+		if d.Day() != day || (dir > 0 && d.Before(today)) || (dir < 0 && d.After(today)) {
+			continue
+		}
+		return d, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,133 @@
+package flagParser
+
+import (
+	"testing"
+	"time"
+)
+
+func _getMonthNameTestFlags() []FlagInfo {
+	var ret []FlagInfo
+
+	f1 := FlagInfo{FlagName: "-b", FlagType: Str, MaxLen: 2000}
+	f2 := FlagInfo{FlagName: "-d", FlagType: DateTime, MaxLen: 40, AllowDateRange: true}
+	f3 := FlagInfo{FlagName: "-p", FlagType: DateTime, MaxLen: 40, MissingYear: PreferPast}
+
+	ret = append(ret, f1, f2, f3)
+	return ret
+}
+
+func _getMonthNameTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "14 mar"},
+		expected:    []string{"-d", "2022-03-14"},
+		name:        "today counts as future",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-d", "1 mar"},
+		expected:    []string{"-d", "2023-03-01"},
+		name:        "future preference",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-p", "1 mar"},
+		expected:    []string{"-p", "2022-03-01"},
+		name:        "past preference",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-p", "december 25th"},
+		expected:    []string{"-p", "2021-12-25"},
+		name:        "past preference month first",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-d", "march 14 2023"},
+		expected:    []string{"-d", "2023-03-14"},
+		name:        "with year",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-d", "2021,", "the", "4th", "of", "July"},
+		expected:    []string{"-d", "2021-07-04"},
+		name:        "split across tokens",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-d", "14-Sept-2023"},
+		expected:    []string{"-d", "2023-09-14"},
+		name:        "dashes",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-d", "29feb"},
+		expected:    []string{"-d", "2024-02-29"},
+		name:        "leap day without year",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-d", "14 mar 9am"},
+		expected:    []string{"-d", "2022-03-14T09:00"},
+		name:        "with clock",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-d", "1 apr:14 apr"},
+		expected:    []string{"-d", "2022-04-01:2022-04-14"},
+		name:        "range",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-d", "20 mar+1w"},
+		expected:    []string{"-d", "2022-03-27"},
+		name:        "expression base",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"buy", "milk", "-d", "june", "1"},
+		expected:    []string{"-d", "2022-06-01", "-b", "buy milk"},
+		name:        "with implicit flag",
+		systemFlags: _getMonthNameTestFlags,
+	}, {
+		args:        []string{"-d", "14", "mars"},
+		expected:    []string{"-d", "2022-03-14"},
+		name:        "localised month",
+		systemFlags: _getMonthNameTestFlags,
+		configure:   _withLocale(French()),
+	}, {
+		args:        []string{"-d", "31 apr 2023"},
+		expected:    []string{},
+		name:        "day not in month",
+		systemFlags: _getMonthNameTestFlags,
+		err:         &InvalidCalendarDateError{},
+	}, {
+		args:        []string{"-d", "30 feb"},
+		expected:    []string{},
+		name:        "day never in month",
+		systemFlags: _getMonthNameTestFlags,
+		err:         &InvalidCalendarDateError{},
+	}, {
+		args:        []string{"-d", "14 mar apr"},
+		expected:    []string{},
+		name:        "two months",
+		systemFlags: _getMonthNameTestFlags,
+		err:         &InvalidDateLiteralError{},
+	}, {
+		args:        []string{"-d", "mar 2023"},
+		expected:    []string{},
+		name:        "no day",
+		systemFlags: _getMonthNameTestFlags,
+		err:         &InvalidDateLiteralError{},
+	}}
+}
+
+func TestMonthNameDates(t *testing.T) {
+	tcs := _getMonthNameTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestInferYear(t *testing.T) {
+	now := time.Date(2023, 03, 01, 15, 0, 0, 0, time.UTC)
+	if d, ok := inferYear(now, time.February, 29, PreferPast); !ok || StringFromDate(d) != "2020-02-29" {
+		t.Errorf(">>>>FAILED: past leap day. \nGot\t'%v'", d)
+	}
+	if d, ok := inferYear(now, time.March, 1, PreferPast); !ok || StringFromDate(d) != "2023-03-01" {
+		t.Errorf(">>>>FAILED: today should count as past. \nGot\t'%v'", d)
+	}
+	if _, ok := inferYear(now, time.February, 30, PreferFuture); ok {
+		t.Errorf(">>>>FAILED: impossible date resolved")
+	}
+}
@@ -0,0 +1,302 @@
+package flagParser
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Used when neither the parser nor the flag sets a separator
+const defaultRangeSeparator = ":"
+
+// Used instead of ':' for timestamp ranges when neither the parser
+// nor the flag sets a separator, as times of day have colons too.
+// Accepted in input for any range.
+const defaultTimestampRangeSeparator = ".."
+
+// Flag separator takes precedence over the parser's
+func (fp *FlagParser) rangeSeparator(fi flag_info_key) string {
+	if fi.rangeSep != "" {
+		return fi.rangeSep
+	}
+	if fp.RangeSeparator != "" {
+		return fp.RangeSeparator
+	}
+	return defaultRangeSeparator
+}
+
+// Why sep can't split ranges, or "" if it can. An empty sep
+// means the default is used.
+func rangeSeparatorProblem(sep string) string {
+	switch {
+	case sep == "":
+		return ""
+	case strings.TrimSpace(sep) == "":
+		return "range separator can't be blank"
+	case strings.ContainsAny(sep, "+-0123456789"):
+		return "range separator clashes with relative date input"
+	}
+	return ""
+}
+
+// True if sep has a '/' & dates can be written with one, either as
+// numeric dates ('14/03/2022') or through a layout
+func (fp *FlagParser) separatorClashesWithDates(sep string) bool {
+	if !strings.Contains(sep, "/") {
+		return false
+	}
+	if len(fp.dateOrders()) > 0 {
+		return true
+	}
+	for _, l := range append(fp.inputLayouts(), fp.outputLayout(), fp.timestampLayout()) {
+		if strings.Contains(l, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (fp *FlagParser) separatorIsDefault(fi flag_info_key) bool {
+	return fi.rangeSep == "" && fp.RangeSeparator == ""
+}
+
+// Separator ranges are written with
+func (fp *FlagParser) outputSeparator(fi flag_info_key, hasTime bool) string {
+	if hasTime && fp.separatorIsDefault(fi) {
+		return defaultTimestampRangeSeparator
+	}
+	return fp.rangeSeparator(fi)
+}
+
+// Splits on the flag's separator, or on '..' if it has none & the
+// input uses it
+func (fp *FlagParser) splitRange(input string, fi flag_info_key) (bool, []string, error) {
+	sep := fp.rangeSeparator(fi)
+	if fp.separatorIsDefault(fi) && strings.Contains(input, defaultTimestampRangeSeparator) {
+		sep = defaultTimestampRangeSeparator
+	}
+	return splitOnSeparator(input, sep)
+}
+
+// ':' needs care as it also appears in times of day. Rather than
+// guess which colons split a range of timestamps, such ranges
+// are rejected. A date & a timestamp split cleanly, but are still
+// a mismatch.
+func splitOnSeparator(input, sep string) (bool, []string, error) {
+	if sep == ":" {
+		isRng, splt, hasClock := checkForDateRange(input)
+		if isRng && hasClock && len(splt) == 2 {
+			_, _, startClock := checkForDateRange(splt[0])
+			if _, _, endClock := checkForDateRange(splt[1]); startClock != endClock {
+				mismatch := &RangeFormatMismatchError{Start: strings.TrimSpace(splt[0]), End: strings.TrimSpace(splt[1])}
+				return false, nil, &MalformedDateRangeError{Err: mismatch}
+			}
+		}
+		if isRng && hasClock {
+			return false, nil, &AmbiguousRangeSeparatorError{Input: input}
+		}
+		return isRng, splt, nil
+	}
+	splt := strings.Split(input, sep)
+	return len(splt) > 1, splt, nil
+}
+
+// Word forms of ranges; a missing capture group is an open end
+var wordRangePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^between\s+(.+?)\s+and\s+(.+)$`),
+	regexp.MustCompile(`^(?:from|since)\s+(.+?)(?:\s+(?:to|until|till)\s+(.+))?$`),
+	regexp.MustCompile(`^()(?:until|till)\s+(.+)$`),
+	regexp.MustCompile(`^(.+?)\s+(?:to|until|till)\s+(.+)$`),
+}
+
+// Checks for 'from X to Y', 'X until Y', 'between X and Y' etc. Relies
+// on handleSpaces having joined the tokens with single spaces.
+func checkForWordRange(input string) (bool, []string) {
+	lower := strings.ToLower(strings.TrimSpace(input))
+
+	for _, p := range wordRangePatterns {
+		if m := p.FindStringSubmatch(lower); m != nil {
+			return true, []string{m[1], m[2]}
+		}
+	}
+	return false, nil
+}
+
+// Comparison prefixes & which side of the range they leave open
+var comparisonOps = []struct {
+	op        string
+	openEnd   bool
+	exclusive bool
+}{
+	{">=", true, false}, {"<=", false, false}, //two-char ops checked first
+	{">", true, true}, {"<", false, true},
+}
+
+// Turns '>3d' or '<=2022-01-01' into a one-sided range.
+// '>' & '<' exclude the given date itself.
+func checkForComparison(input string) (isCmp bool, rng []string, exclusive bool) {
+	trimmed := strings.TrimSpace(input)
+
+	for _, c := range comparisonOps {
+		if !strings.HasPrefix(trimmed, c.op) {
+			continue
+		}
+		bound := strings.TrimPrefix(trimmed, c.op)
+		if c.openEnd {
+			return true, []string{bound, ""}, c.exclusive
+		}
+		return true, []string{"", bound}, c.exclusive
+	}
+	return false, nil, false
+}
+
+// What to do with a range whose start is after its end
+type RangeOrderPolicy int
+
+const (
+	AllowReversed RangeOrderPolicy = iota
+	RejectReversed
+	SwapReversed
+)
+
+// Resolves both ends of a range.
+// An empty end is unbounded & only allowed on flags with AllowOpenRange.
+// Exclusive bounds are moved one step inwards: a day for dates, a minute
+// for timestamps. Only the end can be anchored to the other endpoint; it
+// inherits the start's time of day.
+func (fp *FlagParser) convertRange(rng []string, exclusive bool, fi flag_info_key) (DateRange, error) {
+	if len(rng) != 2 {
+		return DateRange{}, &MalformedDateRangeError{}
+	}
+
+	var ends [2]time.Time
+	var timed [2]bool
+	open := 0
+	for i := range rng {
+		if len(strings.TrimSpace(rng[i])) == 0 {
+			if !fi.allowOpenRange {
+				return DateRange{}, &MalformedDateRangeError{}
+			}
+			rng[i] = ""
+			open++
+			continue
+		}
+
+		var d time.Time
+		var hasTime bool
+		var err error
+		if i == 1 && isAnchored(rng[i]) {
+			if open > 0 {
+				return DateRange{}, &MalformedDateRangeError{} //nothing to anchor to
+			}
+			d, hasTime, err = resolveAnchored(rng[i], ends[0], fp.overflowPolicy(fi), fp.holidaysFor(fi))
+			hasTime = hasTime || timed[0]
+		} else {
+			d, hasTime, err = fp.resolveDate(rng[i], fi)
+			if err == nil && (i == 1) != exclusive {
+				d = fp.weekEnd(rng[i], d, fi) //'W11:W13' & '>W11' take in the whole week
+			}
+		}
+		if _, ok := err.(*InvalidDateLiteralError); ok {
+			return DateRange{}, &MalformedDateRangeError{Err: err}
+		} else if err != nil {
+			return DateRange{}, err
+		}
+
+		if exclusive {
+			d = stepInwards(d, hasTime, i == 0)
+		}
+		if hasTime {
+			d = d.In(fp.outputLocation(fi))
+		}
+		ends[i], timed[i] = d, hasTime
+		rng[i] = fp.formatResolved(d, hasTime, fi)
+	}
+
+	if open == 2 {
+		return DateRange{}, &MalformedDateRangeError{} //':' on its own
+	}
+	if open == 0 && timed[0] != timed[1] { //e.g. '2022-03-14:2022-03-29T09:00'
+		return DateRange{}, &MalformedDateRangeError{Err: &RangeFormatMismatchError{Start: rng[0], End: rng[1]}}
+	}
+
+	if open == 0 && ends[1].Before(ends[0]) {
+		switch fi.rangeOrder {
+		case RejectReversed:
+			return DateRange{}, &ReversedDateRangeError{Start: rng[0], End: rng[1]}
+		case SwapReversed:
+			ends[0], ends[1] = ends[1], ends[0]
+			rng[0], rng[1] = rng[1], rng[0]
+		}
+	}
+
+	if fi.maxSpan != "" {
+		err := checkRangeSpan(ends, open > 0, fi.maxSpan)
+		if err != nil {
+			return DateRange{}, err
+		}
+	}
+
+	dr := DateRange{Start: ends[0], End: ends[1], OpenStart: rng[0] == "", OpenEnd: rng[1] == "",
+		HasTime: timed[0] || timed[1], Separator: fp.outputSeparator(fi, timed[0] || timed[1]), Layout: fp.outputLayout()}
+	if dr.HasTime {
+		dr.Layout = fp.timestampLayout()
+	}
+	return dr, nil
+}
+
+// Checks that the range doesn't cover more than maxSpan (relative
+// shorthand, e.g. '1y'). Open ranges are always too long.
+func checkRangeSpan(ends [2]time.Time, open bool, maxSpan string) error {
+	mp, literal, err := getDateMap(maxSpan)
+	if err != nil || literal {
+		return &DateRangeSpanError{MaxSpan: maxSpan}
+	}
+
+	start, end := ends[0], ends[1]
+	if end.Before(start) {
+		start, end = end, start
+	}
+	limit, _, _ := applyDateMap(start, mp, OverflowNormalise, nil)
+
+	if open || end.After(limit) {
+		return &DateRangeSpanError{MaxSpan: maxSpan}
+	}
+	return nil
+}
+
+// An end starting with '+' counts from the start of the range
+// rather than from NowMoment; e.g. '2022-03-01:+2w'
+func isAnchored(end string) bool {
+	return strings.HasPrefix(strings.TrimSpace(end), "+")
+}
+
+// Applies anchored relative shorthand to start. Literals & keywords
+// can't be anchored as they don't depend on now in the first place.
+//
+// Only a '+' end is anchored: '-1m:+10d' ends 10 days after the
+// start, not 10 days from now as it did before anchoring. Unsigned
+// & '-' ends are still relative to now.
+func resolveAnchored(end string, start time.Time, policy MonthOverflowPolicy, cal HolidayCalendar) (time.Time, bool, error) {
+	noSpaces := strings.ToLower(strings.ReplaceAll(end, " ", ""))
+	mp, literal, err := getDateMap(noSpaces)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if literal {
+		return time.Time{}, false, &MalformedDateRangeError{Err: &UnknownDateInputError{Input: end, Reason: "only relative offsets can be anchored"}}
+	}
+
+	return applyDateMap(start, mp, policy, cal)
+}
+
+func stepInwards(d time.Time, hasTime, isStart bool) time.Time {
+	dir := 1
+	if !isStart {
+		dir = -1
+	}
+	if hasTime {
+		return d.Add(time.Duration(dir) * time.Minute)
+	}
+	return d.AddDate(0, 0, dir)
+}
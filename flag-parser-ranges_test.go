@@ -0,0 +1,421 @@
+package flagParser
+
+import (
+	"testing"
+)
+
+func _getRangeTestFlags() []FlagInfo {
+	var ret []FlagInfo
+
+	f1 := FlagInfo{FlagName: "-b", FlagType: Str, MaxLen: 2000}
+	f2 := FlagInfo{FlagName: "-d", FlagType: DateTime, MaxLen: 40, AllowDateRange: true, AllowOpenRange: true}
+	f3 := FlagInfo{FlagName: "-z", FlagType: DateTime, MaxLen: 40, AllowDateRange: true}
+	f4 := FlagInfo{FlagName: "-r", FlagType: DateTime, MaxLen: 40, AllowDateRange: true, RangeSeparator: ".."}
+
+	f5 := FlagInfo{FlagName: "-x", FlagType: DateTime, MaxLen: 40, AllowDateRange: true, RangeOrder: RejectReversed}
+	f6 := FlagInfo{FlagName: "-w", FlagType: DateTime, MaxLen: 40, AllowDateRange: true, AllowOpenRange: true, RangeOrder: SwapReversed, MaxRangeSpan: "1y"}
+
+	f7 := FlagInfo{FlagName: "-l", FlagType: DateTime, MaxLen: 80, AllowDateRange: true, AllowOpenRange: true, AllowDateList: true}
+	f8 := FlagInfo{FlagName: "-s", FlagType: DateTime, MaxLen: 80, AllowDateList: true}
+
+	ret = append(ret, f1, f2, f3, f4, f5, f6, f7, f8)
+	return ret
+}
+
+func _getOpenRangeTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "-2y:"},
+		expected:    []string{"-d", "2020-03-14:"},
+		name:        "open end",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", ":2022-03-01"},
+		expected:    []string{"-d", ":2022-03-01"},
+		name:        "open start",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", ">3d"},
+		expected:    []string{"-d", "2022-03-18:"},
+		name:        "greater than excludes date",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", ">=3d"},
+		expected:    []string{"-d", "2022-03-17:"},
+		name:        "greater than or equal",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", "<2022-01-01"},
+		expected:    []string{"-d", ":2021-12-31"},
+		name:        "less than excludes date",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", "<=", "2022-01-01"},
+		expected:    []string{"-d", ":2022-01-01"},
+		name:        "less than or equal with space",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", ">9am"},
+		expected:    []string{"-d", "2022-03-14T09:01.."},
+		name:        "greater than timestamp",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"some", "body", "-d", ">1w"},
+		expected:    []string{"-d", "2022-03-22:", "-b", "some body"},
+		name:        "open range with implicit flag",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", ":"},
+		expected:    []string{},
+		name:        "both ends open",
+		systemFlags: _getRangeTestFlags,
+		err:         &MalformedDateRangeError{},
+	}, {
+		args:        []string{"-z", "-2y:"},
+		expected:    []string{},
+		name:        "open range not allowed",
+		systemFlags: _getRangeTestFlags,
+		err:         &MalformedDateRangeError{},
+	}, {
+		args:        []string{"-z", "<=2022-01-01"},
+		expected:    []string{},
+		name:        "comparison not allowed without open ranges",
+		systemFlags: _getRangeTestFlags,
+		err:         &MalformedDateRangeError{},
+	}, {
+		args:        []string{"-z", "-2d:2d:4d"},
+		expected:    []string{},
+		name:        "too many range ends",
+		systemFlags: _getRangeTestFlags,
+		err:         &MalformedDateRangeError{},
+	}}
+}
+
+func TestOpenRanges(t *testing.T) {
+	tcs := _getOpenRangeTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func _getRangeSeparatorTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-r", "9:00..17:00"},
+		expected:    []string{"-r", "2022-03-14T09:00..2022-03-14T17:00"},
+		name:        "flag separator with times",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", "-7d..10d"},
+		expected:    []string{"-d", "2022-03-07..2022-03-24"},
+		name:        "parser separator",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.RangeSeparator = ".." },
+	}, {
+		args:        []string{"-d", "-1w/1w"},
+		expected:    []string{"-d", "2022-03-07/2022-03-21"},
+		name:        "iso 8601 separator",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.RangeSeparator = "/" },
+	}, {
+		args:        []string{"-d", "-1w/1w"},
+		expected:    []string{},
+		name:        "slash separator with numeric dates",
+		systemFlags: _getRangeTestFlags,
+		configure: func(fp *FlagParser) {
+			fp.RangeSeparator = "/"
+			fp.DateOrders = []DateOrder{DMY}
+		},
+		err: &InvalidFlagSpecError{},
+	}, {
+		args:        []string{"-d", "-1w-1w"},
+		expected:    []string{},
+		name:        "parser separator clashes with offsets",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.RangeSeparator = "-" },
+		err:         &InvalidFlagSpecError{},
+	}, {
+		args:        []string{"-d", "today", "1w"},
+		expected:    []string{},
+		name:        "blank parser separator",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.RangeSeparator = " " },
+		err:         &InvalidFlagSpecError{},
+	}, {
+		args:        []string{"-r", "-7d:10d"},
+		expected:    []string{},
+		name:        "default separator not used when flag sets one",
+		systemFlags: _getRangeTestFlags,
+		err:         &InvalidDateLiteralError{},
+	}, {
+		args:        []string{"-z", "from", "-1w", "to", "tomorrow"},
+		expected:    []string{"-z", "2022-03-07:2022-03-15"},
+		name:        "from to",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "last", "friday", "until", "eom"},
+		expected:    []string{"-z", "2022-03-11:2022-03-31"},
+		name:        "until",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-r", "between", "2022-03-01", "and", "2022-03-10"},
+		expected:    []string{"-r", "2022-03-01..2022-03-10"},
+		name:        "between and",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", "since", "-2y"},
+		expected:    []string{"-d", "2020-03-14:"},
+		name:        "since is open ended",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", "until", "friday"},
+		expected:    []string{"-d", ":2022-03-18"},
+		name:        "until is open started",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "from", "2022-03-01"},
+		expected:    []string{},
+		name:        "open word range not allowed",
+		systemFlags: _getRangeTestFlags,
+		err:         &MalformedDateRangeError{},
+	}}
+}
+
+func TestRangeSeparators(t *testing.T) {
+	tcs := _getRangeSeparatorTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func _getRangeOrderTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-d", "10d:-7d"},
+		expected:    []string{"-d", "2022-03-24:2022-03-07"},
+		name:        "reversed range allowed by default",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-x", "10d:-7d"},
+		expected:    []string{},
+		name:        "reversed range rejected",
+		systemFlags: _getRangeTestFlags,
+		err:         &ReversedDateRangeError{},
+	}, {
+		args:        []string{"-x", "-7d:10d"},
+		expected:    []string{"-x", "2022-03-07:2022-03-24"},
+		name:        "ordered range with reject policy",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-w", "10d:-7d"},
+		expected:    []string{"-w", "2022-03-07:2022-03-24"},
+		name:        "reversed range swapped",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-w", "-1y:today"},
+		expected:    []string{"-w", "2021-03-14:2022-03-14"},
+		name:        "range of exactly max span",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-w", "tomorrow:-1y"},
+		expected:    []string{},
+		name:        "swapped range over max span",
+		systemFlags: _getRangeTestFlags,
+		err:         &DateRangeSpanError{},
+	}, {
+		args:        []string{"-w", "-1y:"},
+		expected:    []string{},
+		name:        "open range exceeds max span",
+		systemFlags: _getRangeTestFlags,
+		err:         &DateRangeSpanError{},
+	}}
+}
+
+func TestRangeOrder(t *testing.T) {
+	tcs := _getRangeOrderTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func _getAnchoredRangeTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-z", "2022-03-01:+2w"},
+		expected:    []string{"-z", "2022-03-01:2022-03-15"},
+		name:        "literal start with anchored end",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "-1m:+10d"},
+		expected:    []string{"-z", "2022-02-14:2022-02-24"},
+		name:        "relative start with anchored end",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "+2w:+1w"},
+		expected:    []string{"-z", "2022-03-28:2022-04-04"},
+		name:        "start plus sign still relative to now",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "-1m:-10d"},
+		expected:    []string{"-z", "2022-02-14:2022-03-04"},
+		name:        "negative end relative to now",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "-1m:10d"},
+		expected:    []string{"-z", "2022-02-14:2022-03-24"},
+		name:        "unsigned end relative to now",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "eom:+3b"},
+		expected:    []string{"-z", "2022-03-31:2022-04-05"},
+		name:        "keyword start with anchored business days",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-r", "friday 9am..+2h"},
+		expected:    []string{"-r", "2022-03-18T09:00..2022-03-18T11:00"},
+		name:        "anchored hours from timestamp",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-r", "friday 9am..+1d"},
+		expected:    []string{"-r", "2022-03-18T09:00..2022-03-19T09:00"},
+		name:        "anchored end inherits time of day",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", ":+2w"},
+		expected:    []string{},
+		name:        "anchored end with open start",
+		systemFlags: _getRangeTestFlags,
+		err:         &MalformedDateRangeError{},
+	}, {
+		args:        []string{"-z", "-1m:+friday"},
+		expected:    []string{},
+		name:        "anchored keyword",
+		systemFlags: _getRangeTestFlags,
+		err:         &MalformedDateRangeError{},
+	}}
+}
+
+func TestAnchoredRanges(t *testing.T) {
+	tcs := _getAnchoredRangeTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func _getDateListTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-l", "today,+1w,2022-05-01:2022-05-03"},
+		expected:    []string{"-l", "2022-03-14,2022-03-21,2022-05-01:2022-05-03"},
+		name:        "mixed list",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-l", "2022-05-01,today,-1d"},
+		expected:    []string{"-l", "2022-03-13,2022-03-14,2022-05-01"},
+		name:        "list is sorted",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-l", "today,2022-03-14,0d,tomorrow"},
+		expected:    []string{"-l", "2022-03-14,2022-03-15"},
+		name:        "duplicates removed",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-l", "1w:,:2022-01-01,2022-01-01:2022-02-01"},
+		expected:    []string{"-l", ":2022-01-01,2022-01-01:2022-02-01,2022-03-21:"},
+		name:        "open ranges in list",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-l", "9am,", "tomorrow"},
+		expected:    []string{"-l", "2022-03-14T09:00,2022-03-15"},
+		name:        "list with spaces",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-l", "2022-05-01"},
+		expected:    []string{"-l", "2022-05-01"},
+		name:        "single element",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-s", "today,1d"},
+		expected:    []string{"-s", "2022-03-14,2022-03-15"},
+		name:        "list without ranges",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-s", "march", "14,", "2023,", "april", "1,", "2023"},
+		expected:    []string{"-s", "2023-03-14,2023-04-01"},
+		name:        "comma before year kept in month name dates",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-l", "14", "mar,", "2023:1", "apr,", "2023,1d"},
+		expected:    []string{"-l", "2022-03-15,2023-03-14:2023-04-01"},
+		name:        "comma before year in range sides",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-s", "march", "14,2023-04-01"},
+		expected:    []string{"-s", "2022-03-14,2023-04-01"},
+		name:        "comma before numeric date still splits",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-s", "2022-03-14,", "2023"},
+		expected:    []string{},
+		name:        "year after numeric date isn't joined",
+		systemFlags: _getRangeTestFlags,
+		err:         &InvalidDateLiteralError{},
+	}, {
+		args:        []string{"-s", "today,1d:2d"},
+		expected:    []string{},
+		name:        "range element not allowed",
+		systemFlags: _getRangeTestFlags,
+		err:         &DateRangeNotAllowedError{},
+	}, {
+		args:        []string{"-l", "today,,1d"},
+		expected:    []string{},
+		name:        "empty element",
+		systemFlags: _getRangeTestFlags,
+		err:         &UnknownDateInputError{},
+	}, {
+		args:        []string{"-d", "today,1d"},
+		expected:    []string{},
+		name:        "list not allowed",
+		systemFlags: _getRangeTestFlags,
+		err:         &InvalidDateLiteralError{},
+	}}
+}
+
+func TestDateLists(t *testing.T) {
+	tcs := _getDateListTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestRangeFormatMismatch(t *testing.T) {
+	fp := NewFlagParser(_getRangeTestFlags(), []string{"-z", "2022-03-14..2022-03-29T09:00"}, WithNowAs(returnNowString(), "2006-01-02"))
+	_, err := fp.ParseUserInput()
+
+	me, ok := err.(*MalformedDateRangeError)
+	if !ok {
+		t.Errorf(">>>>FAILED: expected malformed range. \nGot\t'%v'", err)
+		return
+	}
+	if fe, ok := me.Err.(*RangeFormatMismatchError); !ok || fe.Start != "2022-03-14" || fe.End != "2022-03-29T09:00" {
+		t.Errorf(">>>>FAILED: mismatch detail. \nGot\t'%v'", me.Err)
+	}
+
+	for _, arg := range []string{"2022-03-01:2022-03-10T09:00", "2022-03-01T09:00:2022-03-10"} {
+		fp = NewFlagParser(_getRangeTestFlags(), []string{"-z", arg}, WithNowAs(returnNowString(), "2006-01-02"))
+		_, err = fp.ParseUserInput()
+		if me, ok := err.(*MalformedDateRangeError); !ok || !_errorsMatch(me.Err, &RangeFormatMismatchError{}) {
+			t.Errorf(">>>>FAILED: '%v' split on colon. \nGot\t'%v'", arg, err)
+		}
+	}
+
+	fp = NewFlagParser(_getRangeTestFlags(), []string{"-z", "2022-03-01:2022-02-30"}, WithNowAs(returnNowString(), "2006-01-02"))
+	if _, err = fp.ParseUserInput(); !_errorsMatch(err, &InvalidCalendarDateError{}) {
+		t.Errorf(">>>>FAILED: invalid range end. \nGot\t'%v'", err)
+	}
+}
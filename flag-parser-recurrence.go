@@ -0,0 +1,496 @@
+package flagParser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Periods searched for matches before Next gives up; stops rules
+// like 'monthly 5th fri' from looping on months without a match
+const maxRecurrencePeriods = 1000
+
+// A repeating schedule in RFC 5545 terms. ByDay & ByMonthDay narrow
+// each period down to the matching days.
+type RecurrenceRule struct {
+	Freq       string
+	Interval   int
+	ByDay      []RecurrenceDay
+	ByMonthDay []int
+}
+
+// A weekday, optionally the nth (or nth from last if negative)
+// of its month or year. Ordinal 0 means every such weekday.
+type RecurrenceDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// Frequency & interval multiplier for each relative date unit
+var recurrenceUnits = map[string]struct {
+	freq string
+	mult int
+}{
+	"y": {"YEARLY", 1}, "q": {"MONTHLY", 3}, "m": {"MONTHLY", 1}, "w": {"WEEKLY", 1},
+	"d": {"DAILY", 1}, "b": {"DAILY", 1}, "h": {"HOURLY", 1}, "min": {"MINUTELY", 1},
+}
+
+// Word forms of the units above
+var recurrenceWords = map[string]struct {
+	unit string
+	mult int
+}{
+	"daily": {"d", 1}, "weekly": {"w", 1}, "fortnightly": {"w", 2}, "monthly": {"m", 1},
+	"quarterly": {"q", 1}, "yearly": {"y", 1}, "annually": {"y", 1}, "hourly": {"h", 1},
+	"day": {"d", 1}, "week": {"w", 1}, "fortnight": {"w", 2}, "month": {"m", 1}, "quarter": {"q", 1},
+	"year": {"y", 1}, "hour": {"h", 1}, "minute": {"min", 1}, "min": {"min", 1}, "weekday": {"b", 1},
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1,
+}
+
+// Words that only make the shorthand read better
+var recurrenceFillers = map[string]bool{
+	"every": true, "each": true, "on": true, "the": true, "and": true, "of": true,
+}
+
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var (
+	ordinalPattern   = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)$`)
+	rruleDayPattern  = regexp.MustCompile(`^([+-]?\d{1,2})?(SU|MO|TU|WE|TH|FR|SA)$`)
+	businessWeekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+)
+
+// Parses recurrence shorthand ('2w', 'every mon,wed', 'monthly last
+// fri', 'every 2 weeks on tue') or an RRULE ('FREQ=WEEKLY;BYDAY=MO').
+// A bare number or '15th' is a day of the month.
+func ParseRecurrenceRule(input string) (RecurrenceRule, error) {
+	trimmed := strings.TrimSpace(input)
+	upper := strings.ToUpper(trimmed)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		return parseRRule(input, strings.TrimPrefix(upper, "RRULE:"))
+	}
+
+	var rr RecurrenceRule
+	unit, n := "", 0
+	num, ordinal := 0, 0
+	numbered := false //ordinal was written '15th' so can be a day of the month
+	fail := func(reason string, a ...interface{}) (RecurrenceRule, error) {
+		return RecurrenceRule{}, &InvalidRecurrenceError{Input: input, Reason: fmt.Sprintf(reason, a...)}
+	}
+	setUnit := func(u string, mult int) bool {
+		if unit != "" {
+			return false
+		}
+		if num == 0 {
+			num = 1
+		}
+		unit, n, num = u, num*mult, 0
+		return true
+	}
+
+	tokens := strings.Fields(strings.ToLower(strings.ReplaceAll(trimmed, ",", " ")))
+	for _, tok := range tokens {
+		word := strings.TrimSuffix(tok, "s") //'weeks', 'mondays'
+		rw, isWord := recurrenceWords[word]
+		if _, ok := recurrenceWords[tok]; ok {
+			rw, isWord, word = recurrenceWords[tok], true, tok
+		}
+		wd, isDay := weekdayNames[tok]
+		if !isDay {
+			wd, isDay = weekdayNames[word]
+		}
+
+		if num != 0 && !isWord {
+			rr.ByMonthDay = append(rr.ByMonthDay, num) //'monthly on 15'
+			num = 0
+		}
+		if ordinal != 0 && !isDay && word != "day" {
+			if !numbered {
+				return fail("ordinal must be followed by a weekday or 'day'")
+			}
+			rr.ByMonthDay = append(rr.ByMonthDay, ordinal) //'15th'
+			ordinal = 0
+		}
+
+		switch {
+		case recurrenceFillers[tok]:
+		case isDay:
+			rr.ByDay = append(rr.ByDay, RecurrenceDay{Ordinal: ordinal, Weekday: wd})
+			ordinal = 0
+		case ordinal != 0: //'last day'
+			rr.ByMonthDay = append(rr.ByMonthDay, ordinal)
+			ordinal = 0
+		case word == "weekday" && unit != "": //'weekly on weekdays'
+			for _, d := range businessWeekdays {
+				rr.ByDay = append(rr.ByDay, RecurrenceDay{Weekday: d})
+			}
+		case isWord:
+			if !setUnit(rw.unit, rw.mult*recurrenceUnits[rw.unit].mult) {
+				return fail("more than one frequency given")
+			}
+		case ordinalWords[tok] != 0:
+			ordinal, numbered = ordinalWords[tok], false
+		case ordinalPattern.MatchString(tok):
+			ordinal, _ = strconv.Atoi(ordinalPattern.FindStringSubmatch(tok)[1])
+			numbered = true
+			if ordinal == 0 {
+				return fail("'%v' isn't a valid day", tok)
+			}
+		default:
+			if v, err := strconv.Atoi(tok); err == nil && v > 0 {
+				if num != 0 {
+					return fail("unexpected number '%v'", tok)
+				}
+				num = v
+				continue
+			}
+			if contains(dateUnits, tok) {
+				tok = "1" + tok //'w' on its own
+			}
+			mp, literal, err := getDateMap(tok)
+			if err != nil || literal {
+				return fail("unknown word '%v'", tok)
+			}
+			u, v := singleUnit(mp)
+			if u == "" || v <= 0 {
+				return fail("'%v' must be a single positive interval", tok)
+			}
+			num = v
+			if !setUnit(u, recurrenceUnits[u].mult) {
+				return fail("more than one frequency given")
+			}
+		}
+	}
+	if num != 0 {
+		rr.ByMonthDay = append(rr.ByMonthDay, num)
+	}
+	if ordinal != 0 && !numbered {
+		return fail("ordinal must be followed by a weekday or 'day'")
+	} else if ordinal != 0 {
+		rr.ByMonthDay = append(rr.ByMonthDay, ordinal)
+	}
+
+	switch {
+	case unit == "b":
+		if n != 1 || len(rr.ByDay) > 0 {
+			return fail("business days can't be combined with an interval or weekdays")
+		}
+		for _, d := range businessWeekdays {
+			rr.ByDay = append(rr.ByDay, RecurrenceDay{Weekday: d})
+		}
+	case unit == "" && len(rr.ByMonthDay) == 0 && !hasOrdinals(rr.ByDay) && len(rr.ByDay) > 0:
+		unit, n = "w", 1 //'every mon,wed'
+	case unit == "" && (len(rr.ByMonthDay) > 0 || len(rr.ByDay) > 0):
+		unit, n = "m", 1 //'last fri', '15th'
+	case unit == "":
+		return fail("no frequency given")
+	}
+	rr.Freq, rr.Interval = recurrenceUnits[unit].freq, n
+
+	if err := rr.check(); err != nil {
+		return fail("%v", err)
+	}
+	rr.normalise()
+	return rr, nil
+}
+
+// Only the rule parts produced by String are understood
+func parseRRule(input, rule string) (RecurrenceRule, error) {
+	rr := RecurrenceRule{Interval: 1}
+	fail := func(reason string, a ...interface{}) (RecurrenceRule, error) {
+		return RecurrenceRule{}, &InvalidRecurrenceError{Input: input, Reason: fmt.Sprintf(reason, a...)}
+	}
+
+	for _, part := range strings.Split(strings.TrimSpace(rule), ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return fail("malformed rule part '%v'", part)
+		}
+
+		var err error
+		switch kv[0] {
+		case "FREQ":
+			rr.Freq = kv[1]
+		case "INTERVAL":
+			rr.Interval, err = strconv.Atoi(kv[1])
+		case "BYDAY":
+			for _, v := range strings.Split(kv[1], ",") {
+				m := rruleDayPattern.FindStringSubmatch(v)
+				if m == nil {
+					return fail("unknown day '%v'", v)
+				}
+				var ord int
+				if m[1] != "" {
+					ord, _ = strconv.Atoi(m[1])
+				}
+				rr.ByDay = append(rr.ByDay, RecurrenceDay{Ordinal: ord, Weekday: time.Weekday(indexOf(rruleDays, m[2]))})
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(kv[1], ",") {
+				d, e := strconv.Atoi(v)
+				if e != nil {
+					err = e
+				}
+				rr.ByMonthDay = append(rr.ByMonthDay, d)
+			}
+		default:
+			return fail("unsupported rule part '%v'", kv[0])
+		}
+		if err != nil {
+			return fail("invalid number in '%v'", part)
+		}
+	}
+
+	if err := rr.check(); err != nil {
+		return fail("%v", err)
+	}
+	rr.normalise()
+	return rr, nil
+}
+
+func (rr RecurrenceRule) check() error {
+	switch rr.Freq {
+	case "YEARLY", "MONTHLY", "WEEKLY", "DAILY", "HOURLY", "MINUTELY":
+	default:
+		return fmt.Errorf("unknown frequency '%v'", rr.Freq)
+	}
+	if rr.Interval < 1 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	timed := rr.Freq == "HOURLY" || rr.Freq == "MINUTELY"
+	if timed && (len(rr.ByDay) > 0 || len(rr.ByMonthDay) > 0) {
+		return fmt.Errorf("%v rules can't be limited to days", strings.ToLower(rr.Freq))
+	}
+	if len(rr.ByMonthDay) > 0 && rr.Freq != "MONTHLY" && rr.Freq != "YEARLY" {
+		return fmt.Errorf("days of the month need a monthly or yearly rule")
+	}
+	for _, d := range rr.ByMonthDay {
+		if d == 0 || d > 31 || d < -31 {
+			return fmt.Errorf("day of month %v out of range", d)
+		}
+	}
+	for _, d := range rr.ByDay {
+		switch {
+		case d.Ordinal != 0 && rr.Freq != "MONTHLY" && rr.Freq != "YEARLY":
+			return fmt.Errorf("numbered weekdays need a monthly or yearly rule")
+		case rr.Freq == "MONTHLY" && (d.Ordinal > 5 || d.Ordinal < -5):
+			return fmt.Errorf("no month has %v %vs", d.Ordinal, d.Weekday)
+		case d.Ordinal > 53 || d.Ordinal < -53:
+			return fmt.Errorf("no year has %v %vs", d.Ordinal, d.Weekday)
+		}
+	}
+	return nil
+}
+
+// Sorts & dedupes the BY parts so equal rules serialise the same
+func (rr *RecurrenceRule) normalise() {
+	seen := make(map[RecurrenceDay]bool)
+	var days []RecurrenceDay
+	for _, d := range rr.ByDay {
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		a, b := (int(days[i].Weekday)+6)%7, (int(days[j].Weekday)+6)%7 //Monday first
+		if a != b {
+			return a < b
+		}
+		return days[i].Ordinal < days[j].Ordinal
+	})
+	rr.ByDay = days
+
+	seenDay := make(map[int]bool)
+	var mdays []int
+	for _, d := range rr.ByMonthDay {
+		if !seenDay[d] {
+			seenDay[d] = true
+			mdays = append(mdays, d)
+		}
+	}
+	sort.Ints(mdays)
+	rr.ByMonthDay = mdays
+}
+
+// RFC 5545 RRULE value, e.g. 'FREQ=MONTHLY;BYDAY=-1FR'.
+// INTERVAL is left out when it's the default of 1.
+func (rr RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + rr.Freq}
+	if rr.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%v", rr.Interval))
+	}
+	if len(rr.ByDay) > 0 {
+		var days []string
+		for _, d := range rr.ByDay {
+			s := rruleDays[d.Weekday]
+			if d.Ordinal != 0 {
+				s = strconv.Itoa(d.Ordinal) + s
+			}
+			days = append(days, s)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(rr.ByMonthDay) > 0 {
+		var days []string
+		for _, d := range rr.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// The first n occurrences on or after from. from is the start of the
+// series, so day based rules keep its time of day & an interval of 2
+// skips every second period counting from from's. Weeks start on Monday.
+func (rr RecurrenceRule) Next(from time.Time, n int) []time.Time {
+	var ret []time.Time
+	interval := rr.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch rr.Freq {
+	case "HOURLY", "MINUTELY":
+		step := time.Hour
+		if rr.Freq == "MINUTELY" {
+			step = time.Minute
+		}
+		for i := 0; i < n; i++ {
+			ret = append(ret, from.Add(time.Duration(i*interval)*step))
+		}
+		return ret
+	}
+
+	for p := 0; p < maxRecurrencePeriods && len(ret) < n; p++ {
+		start, end := rr.period(from, p*interval)
+		for d := start; d.Before(end) && len(ret) < n; d = d.AddDate(0, 0, 1) {
+			if !d.Before(from) && rr.matches(d, from) {
+				ret = append(ret, d)
+			}
+		}
+	}
+	return ret
+}
+
+// The kth period from the one containing from
+func (rr RecurrenceRule) period(from time.Time, k int) (start, end time.Time) {
+	switch rr.Freq {
+	case "WEEKLY":
+		start = startOfWeek(from).AddDate(0, 0, 7*k)
+		return start, start.AddDate(0, 0, 7)
+	case "MONTHLY":
+		start = startOfMonth(from).AddDate(0, k, 0)
+		return start, start.AddDate(0, 1, 0)
+	case "YEARLY":
+		start = startOfYear(from).AddDate(k, 0, 0)
+		return start, start.AddDate(1, 0, 0)
+	}
+	start = from.AddDate(0, 0, k)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// Rules without BY parts repeat on from's weekday, day or date
+func (rr RecurrenceRule) matches(d, from time.Time) bool {
+	if len(rr.ByDay) == 0 && len(rr.ByMonthDay) == 0 {
+		switch rr.Freq {
+		case "WEEKLY":
+			return d.Weekday() == from.Weekday()
+		case "MONTHLY":
+			return d.Day() == from.Day()
+		case "YEARLY":
+			return d.Month() == from.Month() && d.Day() == from.Day()
+		}
+		return true
+	}
+
+	if len(rr.ByMonthDay) > 0 {
+		last := endOfMonth(d).Day()
+		found := false
+		for _, md := range rr.ByMonthDay {
+			found = found || d.Day() == md || d.Day() == last+md+1
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(rr.ByDay) == 0 {
+		return true
+	}
+
+	pos, last := d.Day(), endOfMonth(d).Day()
+	if rr.Freq == "YEARLY" {
+		pos, last = d.YearDay(), endOfYear(d).YearDay()
+	}
+	for _, bd := range rr.ByDay {
+		if bd.Weekday != d.Weekday() {
+			continue
+		}
+		if bd.Ordinal == 0 || bd.Ordinal == (pos-1)/7+1 || bd.Ordinal == -((last-pos)/7+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses the args of Recurrence flags & replaces them with their RRULE
+func (fp *FlagParser) handleRecurrences(input []string, ufLocations []int) ([]string, error) {
+	for _, v := range ufLocations {
+		flgInf, _ := fp.GetFlagInfoFromName(input[v])
+		if flgInf.flgType != Recurrence {
+			continue
+		}
+
+		rr, err := ParseRecurrenceRule(input[v+1])
+		if err != nil {
+			return nil, err
+		}
+		fp.recurrences[input[v]] = rr
+		input[v+1] = rr.String()
+	}
+	return input, nil
+}
+
+func singleUnit(mp map[string]int) (string, int) {
+	unit, val := "", 0
+	for k, v := range mp {
+		if v == 0 {
+			continue
+		}
+		if unit != "" {
+			return "", 0
+		}
+		unit, val = k, v
+	}
+	return unit, val
+}
+
+func hasOrdinals(days []RecurrenceDay) bool {
+	for _, d := range days {
+		if d.Ordinal != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(sl []string, s string) bool {
+	return indexOf(sl, s) >= 0
+}
+
+func indexOf(sl []string, s string) int {
+	for i, v := range sl {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,186 @@
+package flagParser
+
+import (
+	"testing"
+	"time"
+)
+
+func _getRecurrenceTestFlags() []FlagInfo {
+	var ret []FlagInfo
+
+	f1 := FlagInfo{FlagName: "-b", FlagType: Str, MaxLen: 2000}
+	f2 := FlagInfo{FlagName: "-e", FlagType: Recurrence, MaxLen: 60}
+	f3 := FlagInfo{FlagName: "-d", FlagType: DateTime, MaxLen: 20}
+
+	ret = append(ret, f1, f2, f3)
+	return ret
+}
+
+func _getRecurrenceTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-e", "2w"},
+		expected:    []string{"-e", "FREQ=WEEKLY;INTERVAL=2"},
+		name:        "shorthand interval",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "q"},
+		expected:    []string{"-e", "FREQ=MONTHLY;INTERVAL=3"},
+		name:        "unit without number",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "every", "wed,mon"},
+		expected:    []string{"-e", "FREQ=WEEKLY;BYDAY=MO,WE"},
+		name:        "weekday list",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "monthly", "last", "fri"},
+		expected:    []string{"-e", "FREQ=MONTHLY;BYDAY=-1FR"},
+		name:        "last weekday of month",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "every 2 weeks on tuesday"},
+		expected:    []string{"-e", "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU"},
+		name:        "interval with weekday",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "monthly on the 15th and last day"},
+		expected:    []string{"-e", "FREQ=MONTHLY;BYMONTHDAY=-1,15"},
+		name:        "days of month",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "2nd tue"},
+		expected:    []string{"-e", "FREQ=MONTHLY;BYDAY=2TU"},
+		name:        "numbered weekday implies monthly",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "every weekday"},
+		expected:    []string{"-e", "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR"},
+		name:        "business days",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "fortnightly"},
+		expected:    []string{"-e", "FREQ=WEEKLY;INTERVAL=2"},
+		name:        "fortnightly",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "quarterly"},
+		expected:    []string{"-e", "FREQ=MONTHLY;INTERVAL=3"},
+		name:        "quarterly",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "every quarter"},
+		expected:    []string{"-e", "FREQ=MONTHLY;INTERVAL=3"},
+		name:        "every quarter",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "every 2 quarters"},
+		expected:    []string{"-e", "FREQ=MONTHLY;INTERVAL=6"},
+		name:        "every 2 quarters",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "RRULE:FREQ=YEARLY;INTERVAL=1;BYMONTHDAY=1"},
+		expected:    []string{"-e", "FREQ=YEARLY;BYMONTHDAY=1"},
+		name:        "rrule input normalised",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"some", "body", "-e", "daily", "-d", "1d"},
+		expected:    []string{"-e", "FREQ=DAILY", "-d", "2022-03-15", "-b", "some body"},
+		name:        "alongside other flags",
+		systemFlags: _getRecurrenceTestFlags,
+	}, {
+		args:        []string{"-e", "2w 3d"},
+		expected:    []string{},
+		name:        "two frequencies",
+		systemFlags: _getRecurrenceTestFlags,
+		err:         &InvalidRecurrenceError{},
+	}, {
+		args:        []string{"-e", "weekly last fri"},
+		expected:    []string{},
+		name:        "numbered weekday in weekly rule",
+		systemFlags: _getRecurrenceTestFlags,
+		err:         &InvalidRecurrenceError{},
+	}, {
+		args:        []string{"-e", "hourly on mon"},
+		expected:    []string{},
+		name:        "hourly limited to days",
+		systemFlags: _getRecurrenceTestFlags,
+		err:         &InvalidRecurrenceError{},
+	}, {
+		args:        []string{"-e", "every banana"},
+		expected:    []string{},
+		name:        "unknown word",
+		systemFlags: _getRecurrenceTestFlags,
+		err:         &InvalidRecurrenceError{},
+	}, {
+		args:        []string{"-e", "monthly last"},
+		expected:    []string{},
+		name:        "dangling ordinal",
+		systemFlags: _getRecurrenceTestFlags,
+		err:         &InvalidRecurrenceError{},
+	}}
+}
+
+func TestRecurrenceParsing(t *testing.T) {
+	tcs := _getRecurrenceTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestNextOccurrences(t *testing.T) {
+	cases := []struct {
+		rule     string
+		expected []string
+	}{
+		{"2w", []string{"2022-03-14", "2022-03-28", "2022-04-11"}},
+		{"every mon,wed", []string{"2022-03-14", "2022-03-16", "2022-03-21"}},
+		{"monthly last fri", []string{"2022-03-25", "2022-04-29", "2022-05-27"}},
+		{"monthly on the 31st", []string{"2022-03-31", "2022-05-31", "2022-07-31"}},
+		{"every 2 weeks on tue,sun", []string{"2022-03-15", "2022-03-20", "2022-03-29"}},
+		{"yearly", []string{"2022-03-14", "2023-03-14", "2024-03-14"}},
+		{"every weekday", []string{"2022-03-14", "2022-03-15", "2022-03-16"}},
+		{"monthly 5th sat", []string{"2022-04-30", "2022-07-30", "2022-10-29"}},
+	}
+
+	for _, c := range cases {
+		fp := NewFlagParser(_getRecurrenceTestFlags(), []string{"-e", c.rule}, WithNowAs(returnNowString(), "2006-01-02"))
+		_, err := fp.ParseUserInput()
+		got, ok := fp.NextOccurrences("-e", len(c.expected))
+		if err != nil || !ok {
+			t.Errorf(">>>>FAILED: '%v' not parsed. \nGot\t'%v'", c.rule, err)
+			continue
+		}
+
+		var strs []string
+		for _, d := range got {
+			strs = append(strs, StringFromDate(d))
+		}
+		if _slicesAreTheSame(c.expected, strs) && len(strs) == len(c.expected) {
+			t.Logf(">>>>PASSED: occurrences of '%v'. \nGot\t'%v'", c.rule, strs)
+		} else {
+			t.Errorf(">>>>FAILED: occurrences of '%v'. \nExp\t'%v' \nGot\t'%v'", c.rule, c.expected, strs)
+		}
+	}
+}
+
+func TestRecurrenceRoundTrip(t *testing.T) {
+	for _, in := range []string{"every 3 months on the 2nd fri", "monthly on the last day", "hourly", "every 15 min", "yearly on the first mon"} {
+		rr, err := ParseRecurrenceRule(in)
+		if err != nil {
+			t.Errorf(">>>>FAILED: '%v' not parsed. \nGot\t'%v'", in, err)
+			continue
+		}
+		back, err := ParseRecurrenceRule(rr.String())
+		if err != nil || back.String() != rr.String() {
+			t.Errorf(">>>>FAILED: '%v' doesn't round trip. \nExp\t'%v' \nGot\t'%v' '%v'", in, rr, back, err)
+		}
+	}
+
+	rr, _ := ParseRecurrenceRule("every 90 min")
+	got := rr.Next(time.Date(2022, 03, 14, 9, 0, 0, 0, time.UTC), 2)
+	if len(got) != 2 || got[1].Hour() != 10 || got[1].Minute() != 30 {
+		t.Errorf(">>>>FAILED: timed occurrences. \nGot\t'%v'", got)
+	}
+}
@@ -0,0 +1,171 @@
+package flagParser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Flags that aren't passed fall back to, in order:
+//
+//	argv > EnvVar > Configs (last first) > Default > left out
+//
+// Fallback values go through the same MaxLen, numeric & date
+// handling as argv values, & are appended after them in canonical
+// order. Standalone flags are added if their value is true ('1',
+// 'true' etc.) & left out if false.
+//
+// The implicit flag counts as passed if argv has text for it, even
+// without the flag itself. Without text (eg empty argv) it's dropped
+// & falls back like any other flag.
+
+type SourceKind string
+
+const (
+	FromArgv    SourceKind = "argv"
+	FromEnv     SourceKind = "env"
+	FromConfig  SourceKind = "config"
+	FromDefault SourceKind = "default"
+)
+
+// Where a flag's value came from
+type ValueSource struct {
+	Kind SourceKind
+	Key  string //variable or config key
+	File string //config file path
+	Line int    //config file line
+}
+
+func (vs ValueSource) String() string {
+	switch vs.Kind {
+	case FromEnv:
+		return fmt.Sprintf("env %v", vs.Key)
+	case FromConfig:
+		return fmt.Sprintf("%v:%v (%v)", vs.File, vs.Line, vs.Key)
+	}
+	return string(vs.Kind)
+}
+
+// The first fallback value fi has, & where it came from
+func (fp *FlagParser) fallbackFor(fi FlagInfo) (string, ValueSource, bool) {
+	if fi.EnvVar != "" {
+		if v, ok := fp.lookupEnv(fi.EnvVar); ok {
+			return v, ValueSource{Kind: FromEnv, Key: fi.EnvVar}, true
+		}
+	}
+	if fi.ConfigKey != "" {
+		for i := len(fp.Configs) - 1; i >= 0; i-- {
+			if cv, ok := fp.Configs[i].Values[fi.ConfigKey]; ok {
+				return cv.Value, ValueSource{Kind: FromConfig, Key: fi.ConfigKey, File: fp.Configs[i].Path, Line: cv.Line}, true
+			}
+		}
+	}
+	if fi.Default != "" {
+		return fi.Default, ValueSource{Kind: FromDefault}, true
+	}
+	return "", ValueSource{}, false
+}
+
+// Records argv as the source of the flags in parsed
+func (fp *FlagParser) recordArgvSources(parsed []string) {
+	for _, s := range parsed {
+		if _, ok := fp.GetFlagInfoFromName(s); ok {
+			fp.sources[s] = ValueSource{Kind: FromArgv}
+		}
+	}
+}
+
+// Records argv as the source of parsed flags & appends fallback
+// values for the rest
+func (fp *FlagParser) addFallbackValues(parsed []string) ([]string, error) {
+	parsed = fp.dropEmptyImplicitFlag(parsed)
+	fp.recordArgvSources(parsed)
+
+	for _, fi := range fp.canonicalFlags {
+		if _, passed := fp.sources[fi.FlagName]; passed {
+			continue
+		}
+		v, src, ok := fp.fallbackFor(fi)
+		if !ok {
+			continue
+		}
+
+		vals, err := fp.convertFallbackValue(fi, v)
+		if err != nil {
+			return nil, fallbackError(fi, src, err)
+		}
+		if len(vals) > 0 {
+			fp.sources[fi.FlagName] = src
+		}
+		parsed = append(parsed, vals...)
+	}
+	return parsed, nil
+}
+
+// Removes the implicit flag if nothing follows it but another flag
+func (fp *FlagParser) dropEmptyImplicitFlag(parsed []string) []string {
+	for i, s := range parsed {
+		if s != fp.implicitFlag {
+			continue
+		}
+		if fi, _ := fp.GetFlagInfoFromName(s); fi.standalone {
+			return parsed
+		}
+		if i+1 < len(parsed) {
+			if _, isFlag := fp.GetFlagInfoFromName(parsed[i+1]); !isFlag {
+				return parsed
+			}
+		}
+		return append(parsed[:i:i], parsed[i+1:]...)
+	}
+	return parsed
+}
+
+// Get where a flag's value came from. Only populated by
+// ParseUserInput
+func (fp FlagParser) GetSource(name string) (ValueSource, bool) {
+	v, e := fp.sources[name]
+	return v, e
+}
+
+func fallbackError(fi FlagInfo, src ValueSource, err error) error {
+	switch src.Kind {
+	case FromEnv:
+		return &EnvValueError{Var: src.Key, Flag: fi.FlagName, Err: err}
+	case FromConfig:
+		return &ConfigValueError{Path: src.File, Line: src.Line, Key: src.Key, Flag: fi.FlagName, Err: err}
+	}
+	return &DefaultValueError{Flag: fi.FlagName, Err: err}
+}
+
+// Runs v through the argv handling for a lone flag. There's no
+// implicit flag to take leftovers, so any are an error.
+func (fp *FlagParser) convertFallbackValue(fi FlagInfo, v string) ([]string, error) {
+	if fi.Standalone {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("'%v' isn't true or false", v)
+		}
+		if on {
+			return []string{fi.FlagName}, nil
+		}
+		return nil, nil
+	}
+
+	input, locs := []string{fi.FlagName, v}, []int{0}
+	if input = fp.handleNumericalInput(input, locs); len(input) > 2 {
+		return nil, fmt.Errorf("'%v' isn't a number", v)
+	}
+	arg, remainder := fp.checkAgainstMaxLength(input, 0)
+	if remainder != "" {
+		return nil, &ExceedMaxLengthError{}
+	}
+	input[1] = arg
+
+	var err error
+	for _, handle := range []func([]string, []int) ([]string, error){fp.handleDates, fp.handleRecurrences, fp.handleDurations} {
+		if input, err = handle(input, locs); err != nil {
+			return nil, err
+		}
+	}
+	return input, nil
+}
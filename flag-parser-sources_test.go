@@ -0,0 +1,206 @@
+package flagParser
+
+import (
+	"errors"
+	"testing"
+)
+
+func _getSourceTestFlags() []FlagInfo {
+	var ret []FlagInfo
+
+	f1 := FlagInfo{FlagName: "-b", FlagType: Str, MaxLen: 200}
+	f2 := FlagInfo{FlagName: "-t", FlagType: Str, MaxLen: 10, EnvVar: "TODO_TAG", ConfigKey: "tag", Default: "inbox"}
+	f3 := FlagInfo{FlagName: "-m", FlagType: Integer, MaxLen: 3, ConfigKey: "todo.max"}
+	f4 := FlagInfo{FlagName: "-d", FlagType: DateTime, MaxLen: 40, AllowDateRange: true, ConfigKey: "todo.due", Default: "1d"}
+	f5 := FlagInfo{FlagName: "-u", FlagType: Boolean, Standalone: true, ConfigKey: "urgent"}
+
+	ret = append(ret, f1, f2, f3, f4, f5)
+	return ret
+}
+
+func _getImplicitConfigFlags() []FlagInfo {
+	flags := _getSourceTestFlags()
+	flags[0].ConfigKey = "body" //-b
+	return flags
+}
+
+func _testConfig(path string, vals map[string]string) Config {
+	cfg := Config{Path: path, Values: make(map[string]ConfigValue)}
+	line := 1
+	for k, v := range vals {
+		cfg.Values[k] = ConfigValue{Value: v, Line: line}
+		line++
+	}
+	return cfg
+}
+
+func _withConfigs(env map[string]string, cfgs ...Config) func(*FlagParser) {
+	return func(fp *FlagParser) {
+		_withEnv(env)(fp)
+		fp.Configs = cfgs
+	}
+}
+
+func _getSourceTestCases() []parsing_test_case {
+	system := _testConfig("/etc/todo.toml", map[string]string{"tag": "system", "todo.max": "9"})
+	user := _testConfig("/home/me/.config/todo.toml", map[string]string{"tag": "user", "urgent": "true"})
+
+	return []parsing_test_case{{
+		args:        []string{"-b", "x"},
+		expected:    []string{"-b", "x", "-t", "inbox", "-d", "2022-03-15"},
+		name:        "defaults",
+		systemFlags: _getSourceTestFlags,
+		configure:   _withConfigs(nil),
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{"-b", "x", "-t", "system", "-m", "9", "-d", "2022-03-15"},
+		name:        "config over default",
+		systemFlags: _getSourceTestFlags,
+		configure:   _withConfigs(nil, system),
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{"-b", "x", "-t", "user", "-m", "9", "-d", "2022-03-15", "-u"},
+		name:        "later config over earlier",
+		systemFlags: _getSourceTestFlags,
+		configure:   _withConfigs(nil, system, user),
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{"-b", "x", "-t", "env", "-m", "9", "-d", "2022-03-15"},
+		name:        "env over config",
+		systemFlags: _getSourceTestFlags,
+		configure:   _withConfigs(map[string]string{"TODO_TAG": "env"}, system),
+	}, {
+		args:        []string{"-b", "x", "-t", "argv", "-d", "eow"},
+		expected:    []string{"-b", "x", "-t", "argv", "-d", "2022-03-20", "-m", "9"},
+		name:        "argv over everything",
+		systemFlags: _getSourceTestFlags,
+		configure:   _withConfigs(map[string]string{"TODO_TAG": "env"}, system),
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{},
+		name:        "config value too long",
+		systemFlags: _getSourceTestFlags,
+		configure:   _withConfigs(nil, _testConfig("a.toml", map[string]string{"tag": "far too long for a tag"})),
+		err:         &ConfigValueError{},
+	}, {
+		args:        []string{"-b", "x"},
+		expected:    []string{},
+		name:        "config standalone not boolean",
+		systemFlags: _getSourceTestFlags,
+		configure:   _withConfigs(nil, _testConfig("a.toml", map[string]string{"urgent": "maybe"})),
+		err:         &ConfigValueError{},
+	}, {
+		args:     []string{},
+		expected: []string{"-b", "untitled", "-t", "inbox", "-d", "2022-03-15"},
+		name:     "implicit default without args",
+		systemFlags: func() []FlagInfo {
+			flags := _getSourceTestFlags()
+			flags[0].Default = "untitled" //-b
+			return flags
+		},
+		configure: _withConfigs(nil),
+	}, {
+		args:        []string{},
+		expected:    []string{"-b", "from config", "-t", "inbox", "-d", "2022-03-15"},
+		name:        "implicit config without args",
+		systemFlags: _getImplicitConfigFlags,
+		configure:   _withConfigs(nil, _testConfig("a.toml", map[string]string{"body": "from config"})),
+	}, {
+		args:     []string{"-b", "x"},
+		expected: []string{},
+		name:     "invalid default",
+		systemFlags: func() []FlagInfo {
+			flags := _getSourceTestFlags()
+			flags[3].Default = "banana" //-d
+			return flags
+		},
+		configure: _withConfigs(nil),
+		err:       &DefaultValueError{},
+	}}
+}
+
+func TestFallbackPrecedence(t *testing.T) {
+	tcs := _getSourceTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestValueSources(t *testing.T) {
+	cfg := Config{Path: "/home/me/.config/todo.toml", Values: map[string]ConfigValue{"todo.max": {Value: "9", Line: 4}}}
+	fp := NewFlagParser(_getSourceTestFlags(), []string{"x", "-u"}, WithNowAs(returnNowString(), "2006-01-02"))
+	_withConfigs(map[string]string{"TODO_TAG": "env"}, cfg)(fp)
+
+	if _, err := fp.ParseUserInput(); err != nil {
+		t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+		return
+	}
+
+	exp := map[string]ValueSource{
+		"-b": {Kind: FromArgv},
+		"-u": {Kind: FromArgv},
+		"-t": {Kind: FromEnv, Key: "TODO_TAG"},
+		"-m": {Kind: FromConfig, Key: "todo.max", File: cfg.Path, Line: 4},
+		"-d": {Kind: FromDefault},
+	}
+	for name, src := range exp {
+		got, ok := fp.GetSource(name)
+		if !ok || got != src {
+			t.Errorf(">>>>FAILED: source of '%v'. \nExp\t'%v' \nGot\t'%v'", name, src, got)
+		}
+	}
+	if got, _ := fp.GetSource("-m"); got.String() != "/home/me/.config/todo.toml:4 (todo.max)" {
+		t.Errorf(">>>>FAILED: source string. \nGot\t'%v'", got)
+	}
+
+	fp = NewFlagParser(_getImplicitConfigFlags(), []string{}, WithNowAs(returnNowString(), "2006-01-02"))
+	_withConfigs(nil, Config{Path: "todo.toml", Values: map[string]ConfigValue{"body": {Value: "hi", Line: 2}}})(fp)
+	if _, err := fp.ParseUserInput(); err != nil {
+		t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+	}
+	if got, ok := fp.GetSource("-b"); !ok || got.Kind != FromConfig || got.Line != 2 {
+		t.Errorf(">>>>FAILED: implicit flag from config. \nGot\t'%v'", got)
+	}
+
+	flags := _getSourceTestFlags()
+	flags[0].Default = "untitled" //-b
+	fp = NewFlagParser(flags, []string{}, WithNowAs(returnNowString(), "2006-01-02"))
+	_withConfigs(nil)(fp)
+	if _, err := fp.ParseUserInput(); err != nil {
+		t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+	}
+	if got, ok := fp.GetSource("-b"); !ok || got.Kind != FromDefault {
+		t.Errorf(">>>>FAILED: implicit flag from default. \nGot\t'%v'", got)
+	}
+
+	fp = NewFlagParser([]FlagInfo{{FlagName: "-b", FlagType: Str, MaxLen: 20}, {FlagName: "-u", FlagType: Boolean, Standalone: true}}, []string{"-u"}, WithNowAs(returnNowString(), "2006-01-02"))
+	if got, err := fp.ParseUserInput(); err != nil || !_slicesAreTheSame(got, []string{"-u"}) {
+		t.Errorf(">>>>FAILED: single argv token. \nGot\t'%v' '%v'", got, err)
+	}
+	if got, ok := fp.GetSource("-u"); !ok || got.Kind != FromArgv {
+		t.Errorf(">>>>FAILED: source of a single argv token. \nGot\t'%v' %v", got, ok)
+	}
+}
+
+func TestFallbackErrorDetail(t *testing.T) {
+	fp := NewFlagParser(_getSourceTestFlags(), []string{"-b", "x"}, WithNowAs(returnNowString(), "2006-01-02"))
+	_withConfigs(nil, Config{Path: "todo.json", Values: map[string]ConfigValue{"todo.due": {Value: "2022-02-30", Line: 7}}})(fp)
+
+	_, err := fp.ParseUserInput()
+	var cve *ConfigValueError
+	if !errors.As(err, &cve) || cve.Path != "todo.json" || cve.Line != 7 || cve.Flag != "-d" {
+		t.Errorf(">>>>FAILED: expected config error at todo.json:7. \nGot\t'%v'", err)
+	}
+	var ce *InvalidCalendarDateError
+	if !errors.As(err, &ce) {
+		t.Errorf(">>>>FAILED: expected wrapped calendar error. \nGot\t'%v'", err)
+	}
+
+	flags := _getSourceTestFlags()
+	flags[4].Default = "sometimes" //-u
+	if !_errorsMatch(ValidateFlags(flags), &InvalidFlagSpecError{}) {
+		t.Errorf(">>>>FAILED: expected invalid standalone default")
+	}
+}
@@ -0,0 +1,121 @@
+package flagParser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts time.Format can't express. Either can be used as
+// DateOutputLayout or in DateInputLayouts.
+const (
+	ISOWeekLayout     = "2006-Www" //'2022-W11', the Monday of the ISO 8601 week
+	OrdinalDateLayout = "2006-ddd" //'2022-073', the day of the year
+)
+
+var (
+	isoWeekPattern     = regexp.MustCompile(`^(\d{4})-?[Ww](\d{2})(?:-?([1-7]))?$`)
+	ordinalDatePattern = regexp.MustCompile(`^(\d{4})-(\d{3})$`)
+)
+
+// Formats d with layout, including the week & ordinal layouts
+func formatLayout(d time.Time, layout string) string {
+	switch layout {
+	case ISOWeekLayout:
+		y, w := d.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", y, w)
+	case OrdinalDateLayout:
+		return fmt.Sprintf("%04d-%03d", d.Year(), d.YearDay())
+	}
+	return d.Format(layout)
+}
+
+// time.ParseInLocation, plus the week & ordinal layouts
+func parseLayout(layout, value string, loc *time.Location) (time.Time, error) {
+	var d time.Time
+	var ok bool
+	var err error
+	switch layout {
+	case ISOWeekLayout:
+		d, _, ok, err = parseISOWeek(value, loc)
+	case OrdinalDateLayout:
+		d, ok, err = parseOrdinalDate(value, loc)
+	default:
+		return time.ParseInLocation(layout, value, loc)
+	}
+	if err == nil && !ok {
+		err = &InvalidDateLiteralError{Input: value, Layouts: []string{layout}}
+	}
+	return d, err
+}
+
+// Reads an ISO 8601 week date ('2022-W11', '2022W11', '2022-W11-3').
+// Without a weekday the Monday is returned & wholeWeek is set.
+func parseISOWeek(input string, loc *time.Location) (d time.Time, wholeWeek, ok bool, err error) {
+	m := isoWeekPattern.FindStringSubmatch(strings.TrimSpace(input))
+	if m == nil {
+		return time.Time{}, false, false, nil
+	}
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+	day := 1
+	if m[3] != "" {
+		day, _ = strconv.Atoi(m[3])
+	}
+
+	//Jan 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	week1 := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	d = week1.AddDate(0, 0, 7*(week-1)+day-1)
+	if y, w := d.ISOWeek(); week < 1 || y != year || w != week {
+		return time.Time{}, false, false, &InvalidCalendarDateError{Input: strings.TrimSpace(input), Component: "week"}
+	}
+	return d, m[3] == "", true, nil
+}
+
+// Reads an ordinal date ('2022-073')
+func parseOrdinalDate(input string, loc *time.Location) (time.Time, bool, error) {
+	m := ordinalDatePattern.FindStringSubmatch(strings.TrimSpace(input))
+	if m == nil {
+		return time.Time{}, false, nil
+	}
+	year, _ := strconv.Atoi(m[1])
+	day, _ := strconv.Atoi(m[2])
+
+	d := time.Date(year, time.January, day, 0, 0, 0, 0, loc)
+	if day < 1 || d.Year() != year {
+		return time.Time{}, false, &InvalidCalendarDateError{Input: strings.TrimSpace(input), Component: "day"}
+	}
+	return d, true, nil
+}
+
+// Reads week & ordinal literals whatever the input layouts
+func parseWeekDate(input string, loc *time.Location) (time.Time, bool, error) {
+	if d, _, ok, err := parseISOWeek(input, loc); ok || err != nil {
+		return d, ok, err
+	}
+	return parseOrdinalDate(input, loc)
+}
+
+// The Sunday of a whole ISO week ('2022-W11'); d for anything else
+func (fp *FlagParser) weekEnd(input string, d time.Time, fi flag_info_key) time.Time {
+	monday, wholeWeek, ok, _ := parseISOWeek(input, fp.locationFor(fi))
+	if !ok || !wholeWeek {
+		return d
+	}
+	return monday.AddDate(0, 0, 6)
+}
+
+// A whole ISO week ('2022-W11') as a Monday to Sunday range; only
+// used for flags that allow ranges. Read as 'W11:W11' so the span &
+// order checks apply.
+func (fp *FlagParser) weekRange(input string, fi flag_info_key) (DateRange, bool, error) {
+	_, wholeWeek, ok, err := parseISOWeek(input, fp.locationFor(fi))
+	if !ok || err != nil || !wholeWeek {
+		return DateRange{}, false, err
+	}
+	dr, err := fp.convertRange([]string{input, input}, false, fi)
+	return dr, true, err
+}
@@ -0,0 +1,128 @@
+package flagParser
+
+import (
+	"testing"
+	"time"
+)
+
+func _getWeekDateTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-s", "2022-W11"},
+		expected:    []string{"-s", "2022-03-14"},
+		name:        "week is its monday without ranges",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "2022-W11"},
+		expected:    []string{"-z", "2022-03-14:2022-03-20"},
+		name:        "week is a range with ranges",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-r", "2020w53"},
+		expected:    []string{"-r", "2020-12-28..2021-01-03"},
+		name:        "week crossing the year",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "2022-W11-3"},
+		expected:    []string{"-z", "2022-03-16"},
+		name:        "week day is a single date",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "2022-W11:2022-W13"},
+		expected:    []string{"-z", "2022-03-14:2022-04-03"},
+		name:        "weeks as range sides",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "2022-W11-2:2022-W13-3"},
+		expected:    []string{"-z", "2022-03-15:2022-03-30"},
+		name:        "week days as range sides",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", ">2022-W11"},
+		expected:    []string{"-d", "2022-03-21:"},
+		name:        "after a week",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", "<2022-W11"},
+		expected:    []string{"-d", ":2022-03-13"},
+		name:        "before a week",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-d", "<=2022-W11"},
+		expected:    []string{"-d", ":2022-03-20"},
+		name:        "up to the end of a week",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-s", "2022-073,2024-366"},
+		expected:    []string{"-s", "2022-03-14,2024-12-31"},
+		name:        "ordinal dates",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "2022-W53"},
+		expected:    []string{},
+		name:        "no week 53",
+		systemFlags: _getRangeTestFlags,
+		err:         &InvalidCalendarDateError{},
+	}, {
+		args:     []string{"-z", "2022-W11"},
+		expected: []string{},
+		name:     "week over max span",
+		systemFlags: func() []FlagInfo {
+			flags := _getRangeTestFlags()
+			flags[2].MaxRangeSpan = "3d" //-z
+			return flags
+		},
+		err: &DateRangeSpanError{},
+	}, {
+		args:        []string{"-s", "2022-366"},
+		expected:    []string{},
+		name:        "no day 366",
+		systemFlags: _getRangeTestFlags,
+		err:         &InvalidCalendarDateError{},
+	}, {
+		args:        []string{"-s", "2d"},
+		expected:    []string{"-s", "2022-W11"},
+		name:        "week output",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.DateOutputLayout = ISOWeekLayout },
+	}, {
+		args:        []string{"-z", "2022-W11"},
+		expected:    []string{"-z", "2022-W11:2022-W11"},
+		name:        "week range output",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.DateOutputLayout = ISOWeekLayout },
+	}, {
+		args:        []string{"-z", "2022-01-01:eom"},
+		expected:    []string{"-z", "2022-001:2022-090"},
+		name:        "ordinal output",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.DateOutputLayout = OrdinalDateLayout },
+	}}
+}
+
+func TestWeekDates(t *testing.T) {
+	tcs := _getWeekDateTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+func TestWeekLayoutRoundTrip(t *testing.T) {
+	for _, layout := range []string{ISOWeekLayout, OrdinalDateLayout} {
+		for _, d := range []time.Time{time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC)} {
+			got, err := parseLayout(layout, formatLayout(d, layout), time.UTC)
+			if err != nil || !got.Equal(d) {
+				t.Errorf(">>>>FAILED: layout '%v'. \nExp\t'%v' \nGot\t'%v' '%v'", layout, d, got, err)
+			}
+		}
+	}
+
+	dr, err := ParseDateRange("2022-W11:2022-W12", ":", ISOWeekLayout)
+	if err != nil || dr.Start.Day() != 14 || dr.End.Day() != 21 {
+		t.Errorf(">>>>FAILED: week range. \nGot\t'%v' '%v'", dr, err)
+	}
+	if _, err := parseLayout(ISOWeekLayout, "2022-03-14", time.UTC); !_errorsMatch(err, &InvalidDateLiteralError{}) {
+		t.Errorf(">>>>FAILED: expected mismatch error. \nGot\t'%v'", err)
+	}
+}
@@ -0,0 +1,52 @@
+package flagParser
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata" //zone suffixes work the same on hosts without a zoneinfo database
+)
+
+// Zone used to interpret an arg: a zone suffix on the arg itself,
+// then the flag's Location, then the parser's
+func (fp *FlagParser) locationFor(fi flag_info_key) *time.Location {
+	if fi.zoneSuffix != nil {
+		return fi.zoneSuffix
+	}
+	return fp.outputLocation(fi)
+}
+
+// Zone timestamps are reported in; ignores zone suffixes so
+// every arg of a flag comes out in the same zone. Defaults to
+// NowMoment's zone, never the host's.
+func (fp *FlagParser) outputLocation(fi flag_info_key) *time.Location {
+	if fi.location != nil {
+		return fi.location
+	}
+	if fp.Location != nil {
+		return fp.Location
+	}
+	return fp.NowMoment.Location()
+}
+
+// NowMoment as seen from the zone the arg is interpreted in
+func (fp *FlagParser) nowFor(fi flag_info_key) time.Time {
+	return fp.NowMoment.In(fp.locationFor(fi))
+}
+
+var zoneSuffixPattern = regexp.MustCompile(`^(.*\S)\s+((?:[A-Za-z_]+/)+[A-Za-z0-9_+\-]+|UTC)$`)
+
+// Splits a trailing IANA zone name ('tomorrow 9am Europe/Dublin')
+// from input. Returns nil if there's no suffix.
+func splitZoneSuffix(input string) (string, *time.Location, error) {
+	m := zoneSuffixPattern.FindStringSubmatch(strings.TrimSpace(input))
+	if m == nil {
+		return input, nil, nil
+	}
+
+	loc, err := time.LoadLocation(m[2])
+	if err != nil {
+		return "", nil, &UnknownTimeZoneError{Zone: m[2]}
+	}
+	return m[1], loc, nil
+}
@@ -0,0 +1,96 @@
+package flagParser
+
+import (
+	"testing"
+	"time"
+)
+
+func _mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+func _getTimeZoneTestCases() []parsing_test_case {
+	return []parsing_test_case{{
+		args:        []string{"-z", "today"},
+		expected:    []string{"-z", "2022-03-13"},
+		name:        "parser location moves now",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.Location = _mustLoadLocation("America/New_York") },
+	}, {
+		args:        []string{"-z", "9am"},
+		expected:    []string{"-z", "2022-03-13T09:00"},
+		name:        "clock literal in parser location",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.Location = _mustLoadLocation("America/New_York") },
+	}, {
+		args:        []string{"-z", "tomorrow", "9am", "Asia/Tokyo"},
+		expected:    []string{"-z", "2022-03-15T00:00"},
+		name:        "zone suffix reported in parser location",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "9am:5pm", "Asia/Tokyo"},
+		expected:    []string{"-z", "2022-03-14T00:00..2022-03-14T08:00"},
+		name:        "zone suffix applies to both range ends",
+		systemFlags: _getRangeTestFlags,
+	}, {
+		args:        []string{"-z", "tomorrow", "Asia/Tokyo"},
+		expected:    []string{"-z", "2022-03-15"},
+		name:        "dates keep their day in suffix zone",
+		systemFlags: _getRangeTestFlags,
+		configure:   func(fp *FlagParser) { fp.Location = _mustLoadLocation("America/New_York") },
+	}, {
+		args:     []string{"-z", "today"},
+		expected: []string{"-z", "2022-03-14"},
+		name:     "flag location overrides parser location",
+		systemFlags: func() []FlagInfo {
+			flags := _getRangeTestFlags()
+			flags[2].Location = time.UTC //-z
+			return flags
+		},
+		configure: func(fp *FlagParser) { fp.Location = _mustLoadLocation("America/New_York") },
+	}, {
+		args:        []string{"-z", "tomorrow", "Mars/Olympus_Mons"},
+		expected:    []string{},
+		name:        "unknown zone suffix",
+		systemFlags: _getRangeTestFlags,
+		err:         &UnknownTimeZoneError{},
+	}}
+}
+
+func TestTimeZones(t *testing.T) {
+	tcs := _getTimeZoneTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_runParseTest(t, tc)
+		})
+	}
+}
+
+// Same input must give the same output whatever the host zone is
+func TestHostZoneIgnored(t *testing.T) {
+	hostZone := time.Local
+	defer func() { time.Local = hostZone }()
+
+	var results []string
+	for _, zone := range []string{"Pacific/Kiritimati", "Pacific/Pago_Pago", "UTC"} {
+		time.Local = _mustLoadLocation(zone)
+		fp := NewFlagParser(_getRangeTestFlags(), []string{"-z", "tomorrow", "9am"}, WithNowAs(returnNowString(), "2006-01-02"))
+		got, err := fp.ParseUserInput()
+		if err != nil {
+			t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+			return
+		}
+		results = append(results, got[1])
+	}
+
+	for _, r := range results {
+		if r != "2022-03-15T09:00" {
+			t.Errorf(">>>>FAILED: host zone changed output. \nGot\t'%v'", results)
+			return
+		}
+	}
+}
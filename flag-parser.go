@@ -23,9 +23,43 @@ type FlagParser struct {
 	user_intKey     map[int]string
 	user_strKey     map[string]int
 	implicitFlag    string
+	nowErr          error
+	dateRanges      map[string]DateRange
+	dateLists       map[string][]DateRange
+	recurrences     map[string]RecurrenceRule
+	durations       map[string]CalendarDuration
+	sources         map[string]ValueSource
 	HasUnknownFlags bool
 	DateTimeLayout  string
 	NowMoment       time.Time
+
+	// Layout for resolved dates; defaults to DateTimeLayout. Can
+	// also be ISOWeekLayout or OrdinalDateLayout
+	DateOutputLayout string
+	// Layouts accepted for literal dates; defaults to DateTimeLayout
+	// & DateOutputLayout
+	DateInputLayouts []string
+	// Layout for resolved dates with a time of day
+	TimestampLayout string
+	// Splits & joins range ends ('..', '/'); defaults to ':', or
+	// '..' for ranges with a time of day. '/' can't be used with
+	// DateOrders or layouts that have '/' in them
+	RangeSeparator string
+	// Month & year arithmetic past the end of the target month
+	MonthOverflow MonthOverflowPolicy
+	// Zone dates are resolved & reported in; defaults to NowMoment's
+	Location *time.Location
+	// Language for date words & units on top of English; see French() etc.
+	Locale *Locale
+	// Field orders tried on numeric dates ('14/03/2022'); defaults
+	// to the Locale's. Layouts in DateInputLayouts are tried first
+	DateOrders []DateOrder
+	// Days business day offsets skip besides weekends; see LoadICSHolidays
+	Holidays HolidayCalendar
+	// Reads FlagInfo.EnvVar values; defaults to os.LookupEnv
+	LookupEnv func(string) (string, bool)
+	// Values for flags with a ConfigKey; later configs take precedence
+	Configs []Config
 }
 
 type FlagDataType string
@@ -35,6 +69,11 @@ const (
 	Integer  FlagDataType = "int"
 	Boolean  FlagDataType = "bool"
 	DateTime FlagDataType = "dateTime"
+	// Repeating schedule ('every 2 weeks on mon'); args become RRULEs
+	Recurrence FlagDataType = "recurrence"
+	// Length of time ('P1DT2H', '1h30m', '3d'); args become ISO 8601.
+	// See flag-parser-duration.go for how 'm' is read
+	Duration FlagDataType = "duration"
 )
 
 type FlagInfo struct {
@@ -43,32 +82,121 @@ type FlagInfo struct {
 	MaxLen         int
 	Standalone     bool
 	AllowDateRange bool
+	// Allows ranges with an unbounded end ('-2y:', ':2022-03-01',
+	// '>3d'). Requires AllowDateRange
+	AllowOpenRange bool
+	// Splits & joins range ends; overrides FlagParser.RangeSeparator
+	RangeSeparator string
+	// Handling of ranges whose start is after their end
+	RangeOrder RangeOrderPolicy
+	// Longest range allowed, in relative shorthand ('1y', '2w')
+	MaxRangeSpan string
+	// Overrides FlagParser.MonthOverflow
+	MonthOverflow MonthOverflowPolicy
+	// Overrides FlagParser.Location
+	Location *time.Location
+	// Accepts comma-separated dates & ranges ('today,+1w'). A comma
+	// before a year stays part of a month name date ('mar 14, 2023')
+	AllowDateList bool
+	// Year given to dates without one ('14 mar')
+	MissingYear YearPreference
+	// Overrides FlagParser.Holidays
+	Holidays HolidayCalendar
+	// Variable read when the flag isn't passed; see flag-parser-sources.go
+	EnvVar string
+	// Key read from FlagParser.Configs when the flag isn't passed
+	// or set in the environment ('tag', 'todo.due')
+	ConfigKey string
+	// Value used when the flag isn't passed, set or configured
+	Default string
 }
 
 type flag_info_key struct {
-	index      int
-	flgType    FlagDataType
-	maxLen     int
-	standalone bool
-	allowRange bool
+	index          int
+	flgType        FlagDataType
+	maxLen         int
+	standalone     bool
+	allowRange     bool
+	allowOpenRange bool
+	rangeSep       string
+	rangeOrder     RangeOrderPolicy
+	maxSpan        string
+	monthOverflow  MonthOverflowPolicy
+	location       *time.Location
+	zoneSuffix     *time.Location //set per arg, e.g. 'tomorrow 9am Europe/Dublin'
+	allowList      bool
+	missingYear    YearPreference
+	holidays       HolidayCalendar
 }
 
 type NowMomentFunc func(*FlagParser)
 
+// Supplies the moment relative dates are resolved against
+type Clock interface {
+	Now() time.Time
+}
+
+// Parses nowStr with dateTimeFormat. A parse failure is kept on the
+// parser & returned by the checked constructors and date handling.
 func WithNowAs(nowStr, dateTimeFormat string) NowMomentFunc {
 	return func(fp *FlagParser) {
-		fp.DateTimeLayout = dateTimeFormat
-		fp.NowMoment, _ = time.Parse(fp.DateTimeLayout, nowStr)
+		fp.setNowFromString(nowStr, dateTimeFormat)
+	}
+}
+
+// Uses t as now, without formatting it into a string first.
+// DateTimeLayout defaults to 'YYYY-MM-DD'
+func WithNowMoment(t time.Time) NowMomentFunc {
+	return func(fp *FlagParser) {
+		fp.NowMoment = t
+		fp.setDefaultLayout()
+	}
+}
+
+// Asks c for now once, when the parser is set up. DateTimeLayout
+// defaults to 'YYYY-MM-DD'
+func WithClock(c Clock) NowMomentFunc {
+	return func(fp *FlagParser) {
+		fp.NowMoment = c.Now()
+		fp.setDefaultLayout()
+	}
+}
+
+func (fp *FlagParser) setDefaultLayout() {
+	if fp.DateTimeLayout == "" {
+		fp.DateTimeLayout = defaultDateLayout
 	}
 }
 
 func NewParser(allFlags []FlagInfo, userFlags []string, nowStr, dateFormat string) *FlagParser {
 	fp := setup(allFlags, userFlags)
-	fp.DateTimeLayout = dateFormat
-	fp.NowMoment, _ = time.Parse(dateFormat, nowStr)
+	fp.setNowFromString(nowStr, dateFormat)
 	return fp
 }
 
+// Same as NewParser but validates allFlags & returns any
+// error from parsing nowStr
+func NewCheckedParser(allFlags []FlagInfo, userFlags []string, nowStr, dateFormat string) (*FlagParser, error) {
+	err := ValidateFlags(allFlags)
+	if err != nil {
+		return nil, err
+	}
+	fp := NewParser(allFlags, userFlags, nowStr, dateFormat)
+	if fp.nowErr != nil {
+		return nil, fp.nowErr
+	}
+	return fp, nil
+}
+
+func (fp *FlagParser) setNowFromString(nowStr, layout string) {
+	var err error
+	fp.DateTimeLayout = layout
+	fp.NowMoment, err = time.Parse(layout, nowStr)
+	if err != nil {
+		fp.nowErr = &NowMomentParseError{Input: nowStr, Layout: layout, Err: err}
+	}
+}
+
 // Sets up a new FlagParser. allFlags[0] assumed to be implicit flag
 func NewFlagParser(allFlags []FlagInfo, userFlags []string, nowFunc NowMomentFunc) *FlagParser {
 
@@ -77,6 +205,97 @@ func NewFlagParser(allFlags []FlagInfo, userFlags []string, nowFunc NowMomentFun
 	return fp
 }
 
+// Validates allFlags before setting up a new FlagParser. Unlike
+// NewFlagParser, problems with the canonical flags are returned
+// rather than surfacing as odd parsing results later on.
+func NewCheckedFlagParser(allFlags []FlagInfo, userFlags []string, nowFunc NowMomentFunc) (*FlagParser, error) {
+	err := ValidateFlags(allFlags)
+	if err != nil {
+		return nil, err
+	}
+	fp := NewFlagParser(allFlags, userFlags, nowFunc)
+	if fp.nowErr != nil {
+		return nil, fp.nowErr
+	}
+	return fp, nil
+}
+
+// Checks the whole canonical flag list and reports every problem
+// found. allFlags[0] is the implicit flag, so it must take an arg.
+func ValidateFlags(allFlags []FlagInfo) error {
+	var issues []FlagSpecIssue
+	if len(allFlags) == 0 {
+		issues = append(issues, FlagSpecIssue{Index: -1, Reason: "no flags provided"})
+		return &InvalidFlagSpecError{Issues: issues}
+	}
+
+	seen := make(map[string]int)
+	for i, fi := range allFlags {
+		add := func(reason string) {
+			issues = append(issues, FlagSpecIssue{Index: i, FlagName: fi.FlagName, Reason: reason})
+		}
+
+		if len(fi.FlagName) < 2 || !strings.HasPrefix(fi.FlagName, "-") {
+			add("name must be a '-' followed by at least one character")
+		} else if _, err := strconv.Atoi(string([]rune(fi.FlagName)[1])); err == nil {
+			add("name clashes with negative number input")
+		}
+
+		if j, dup := seen[fi.FlagName]; dup {
+			add(fmt.Sprintf("duplicate of flag at index %v", j))
+		} else {
+			seen[fi.FlagName] = i
+		}
+
+		switch fi.FlagType {
+		case Str, Integer, DateTime, Recurrence, Duration:
+			if fi.Standalone {
+				add(fmt.Sprintf("standalone flags must be of type %v", Boolean))
+			} else if fi.MaxLen <= 0 {
+				add("MaxLen must be greater than zero")
+			}
+		case Boolean:
+		default:
+			add(fmt.Sprintf("unknown flag type '%v'", fi.FlagType))
+		}
+
+		if fi.AllowDateRange && fi.FlagType != DateTime {
+			add(fmt.Sprintf("date ranges only allowed with type %v", DateTime))
+		}
+		if fi.AllowDateList && fi.FlagType != DateTime {
+			add(fmt.Sprintf("date lists only allowed with type %v", DateTime))
+		}
+		if fi.MissingYear != PreferFuture && fi.FlagType != DateTime {
+			add(fmt.Sprintf("year preference only allowed with type %v", DateTime))
+		}
+		if fi.AllowOpenRange && !fi.AllowDateRange {
+			add("open ranges require AllowDateRange")
+		}
+		if reason := rangeSeparatorProblem(fi.RangeSeparator); reason != "" {
+			add(reason)
+		}
+		if fi.MaxRangeSpan != "" {
+			if _, literal, err := getDateMap(fi.MaxRangeSpan); err != nil || literal {
+				add(fmt.Sprintf("unknown max range span '%v'", fi.MaxRangeSpan))
+			}
+		}
+		if strings.ContainsAny(fi.EnvVar, "= \t") {
+			add(fmt.Sprintf("invalid environment variable name '%v'", fi.EnvVar))
+		}
+		if _, err := strconv.ParseBool(fi.Default); fi.Standalone && fi.Default != "" && err != nil {
+			add("default for standalone flags must be true or false")
+		}
+		if i == 0 && fi.Standalone {
+			add("implicit flag can't be standalone")
+		}
+	}
+
+	if len(issues) > 0 {
+		return &InvalidFlagSpecError{Issues: issues}
+	}
+	return nil
+}
+
 func setup(allFlags []FlagInfo, userFlags []string) *FlagParser {
 	fp := FlagParser{canonicalFlags: allFlags}
 	fp.userPassedFlags = append(fp.userPassedFlags, userFlags)
@@ -84,10 +303,14 @@ func setup(allFlags []FlagInfo, userFlags []string) *FlagParser {
 
 	fp.system_intKey = make(map[int]FlagInfo)
 	fp.system_strKey = make(map[string]flag_info_key)
+	fp.resetTypedResults()
 
 	for i, fi := range allFlags {
 		fp.system_intKey[i] = fi
-		fik := flag_info_key{index: i, flgType: fi.FlagType, maxLen: fi.MaxLen, standalone: fi.Standalone, allowRange: fi.AllowDateRange}
+		fik := flag_info_key{index: i, flgType: fi.FlagType, maxLen: fi.MaxLen, standalone: fi.Standalone, allowRange: fi.AllowDateRange,
+			allowOpenRange: fi.AllowOpenRange, rangeSep: fi.RangeSeparator, rangeOrder: fi.RangeOrder, maxSpan: fi.MaxRangeSpan,
+			monthOverflow: fi.MonthOverflow, location: fi.Location, allowList: fi.AllowDateList,
+			missingYear: fi.MissingYear, holidays: fi.Holidays}
 		fp.system_strKey[fi.FlagName] = fik
 	}
 
@@ -99,10 +322,45 @@ func setup(allFlags []FlagInfo, userFlags []string) *FlagParser {
 	return &fp
 }
 
+// Clears what the last ParseUserInput stored for the typed getters
+func (fp *FlagParser) resetTypedResults() {
+	fp.dateRanges = make(map[string]DateRange)
+	fp.dateLists = make(map[string][]DateRange)
+	fp.recurrences = make(map[string]RecurrenceRule)
+	fp.durations = make(map[string]CalendarDuration)
+	fp.sources = make(map[string]ValueSource)
+}
+
 func (fp *FlagParser) CheckInitialisation() error {
 	if fp.system_intKey == nil || fp.system_strKey == nil {
 		return &FlagMapperInitialisationError{}
 	}
+	if err := ValidateFlags(fp.canonicalFlags); err != nil {
+		return err
+	}
+	return fp.checkSettings()
+}
+
+// Checks parser-wide settings that depend on each other. Problems
+// are reported like flag spec issues, at index -1 for the parser.
+func (fp *FlagParser) checkSettings() error {
+	var issues []FlagSpecIssue
+	reason := rangeSeparatorProblem(fp.RangeSeparator)
+	if reason == "" && fp.separatorClashesWithDates(fp.RangeSeparator) {
+		reason = "range separator clashes with '/' in dates"
+	}
+	if reason != "" {
+		issues = append(issues, FlagSpecIssue{Index: -1, Reason: "RangeSeparator: " + reason})
+	}
+	for i, fi := range fp.canonicalFlags {
+		if fp.separatorClashesWithDates(fi.RangeSeparator) {
+			issues = append(issues, FlagSpecIssue{Index: i, FlagName: fi.FlagName, Reason: "range separator clashes with '/' in dates"})
+		}
+	}
+
+	if len(issues) > 0 {
+		return &InvalidFlagSpecError{Issues: issues}
+	}
 	return nil
 }
 
@@ -165,6 +423,45 @@ func (fp FlagParser) GetIndexFromFlagValue(fType flag_origin, flag string) (int,
 	return -1, false
 }
 
+// Get the typed range for a DateTime flag whose arg was a
+// range. Only populated by ParseUserInput
+func (fp FlagParser) GetDateRange(name string) (DateRange, bool) {
+	v, e := fp.dateRanges[name]
+	return v, e
+}
+
+// Get the typed dates & ranges for a DateTime flag with
+// AllowDateList. Only populated by ParseUserInput
+func (fp FlagParser) GetDateList(name string) ([]DateRange, bool) {
+	v, e := fp.dateLists[name]
+	return v, e
+}
+
+// Get the parsed rule for a Recurrence flag. Only populated
+// by ParseUserInput
+func (fp FlagParser) GetRecurrence(name string) (RecurrenceRule, bool) {
+	v, e := fp.recurrences[name]
+	return v, e
+}
+
+// Get the next n occurrences of a Recurrence flag's rule,
+// counting from NowMoment
+func (fp FlagParser) NextOccurrences(name string, n int) ([]time.Time, bool) {
+	rr, ok := fp.recurrences[name]
+	if !ok {
+		return nil, false
+	}
+	fi, _ := fp.GetFlagInfoFromName(name)
+	return rr.Next(fp.nowFor(fi), n), true
+}
+
+// Get the parsed duration for a Duration flag. Only populated
+// by ParseUserInput
+func (fp FlagParser) GetDuration(name string) (CalendarDuration, bool) {
+	v, e := fp.durations[name]
+	return v, e
+}
+
 // Get flag details from flag name. Canonical only
 func (fp FlagParser) GetFlagInfoFromName(name string) (flag_info_key, bool) {
 	v, e := fp.system_strKey[name]
@@ -197,17 +494,21 @@ func (fp *FlagParser) GetLatestFlagLocations() []int {
 // Also handles implicit flags - or flags that can be assumed even if not provided.
 func (fp *FlagParser) ParseUserInput() ([]string, error) {
 	var newArgs []string
+	fp.resetTypedResults()
 	if fp.HasUnknownFlags {
 		return newArgs, &UserArgsContainsUnknownFlag{}
 	}
-
-	if len(fp.userPassedFlags[0]) == 1 {
-		return fp.userPassedFlags[0], nil
+	if err := fp.checkSettings(); err != nil {
+		return nil, err
 	}
+
 	newArgs, err := fp.parse()
 	if err != nil {
 		return nil, err
 	}
+	if newArgs, err = fp.addFallbackValues(newArgs); err != nil {
+		return nil, err
+	}
 	fp.updateUserMaps(newArgs)
 
 	return newArgs, nil
@@ -246,6 +547,14 @@ func (fp *FlagParser) parse() ([]string, error) {
 	if err != nil {
 		return ret, err
 	}
+	ret, err = fp.handleRecurrences(ret, ufLocations)
+	if err != nil {
+		return ret, err
+	}
+	ret, err = fp.handleDurations(ret, ufLocations)
+	if err != nil {
+		return ret, err
+	}
 
 	if removed {
 		ret = fp.reassemble(ret, standalones)
@@ -506,146 +815,6 @@ func (fp *FlagParser) implicitFlagRequired(ufLocations []int, input []string) bo
 	return required
 }
 
-// Checks args of DateTime flags for literal date strings
-// and date relative shorthand ('3d 9m 4y')
-func (fp *FlagParser) handleDates(input []string, ufLocations []int) ([]string, error) {
-
-	for _, v := range ufLocations {
-
-		flgInf, _ := fp.GetFlagInfoFromName(input[v])
-		if flgInf.flgType != DateTime {
-			continue
-		}
-
-		noSpaces := strings.ToLower(strings.ReplaceAll(input[v+1], " ", ""))
-		var retVal string
-		var err error
-
-		isRng, rng := checkForDateRange(noSpaces)
-		if isRng && !flgInf.allowRange {
-			return nil, &DateRangeNotAllowedError{}
-		}
-		if !isRng {
-			//keep using input as is
-			retVal, err = convertToDateString(noSpaces, fp.NowMoment.Local())
-			if err != nil {
-				return nil, err
-			}
-
-		} else {
-			//use rng[0] & then rng[1]
-			rng[0], err = convertToDateString(rng[0], fp.NowMoment.Local())
-			if err != nil {
-				return nil, err
-			}
-
-			rng[1], err = convertToDateString(rng[1], fp.NowMoment.Local())
-			if err != nil {
-				return nil, err
-			}
-
-			if len(rng[0]) != len(rng[1]) { //e.g. '2022-03-14:2022-03-29' vs. '2022-03-14:'
-				return nil, &MalformedDateRangeError{}
-			}
-			retVal = rng[0] + ":" + rng[1]
-		}
-
-		input[v+1] = retVal
-	}
-	return input, nil
-}
-
-func checkForDateRange(input string) (bool, []string) {
-	res := false
-	splt := strings.Split(input, ":")
-	if len(splt) > 1 {
-		res = true
-	}
-	return res, splt
-}
-
-// Checks for existence/location of date identifiers ('y', 'm', 'd')
-// in '3d1m5y' format. Populates date identifier map with relevant values.
-func getDateMap(inputStr string) (mp map[string]int, literalDateStr bool, e error) {
-	letterLocs := []int{}
-	mp = getEmptyDateMap()
-	e = nil
-	literalDateStr = true
-
-	for i, v := range []rune(inputStr) {
-		if string(v) == "y" || string(v) == "m" || string(v) == "d" {
-			letterLocs = append(letterLocs, i)
-			literalDateStr = false
-		}
-	}
-
-	//pupulate date map
-	start := 0
-	for _, v := range letterLocs {
-
-		dateIdfr := string(rune(inputStr[v]))
-
-		if _, exists := mp[dateIdfr]; exists {
-			intPrefix, e := strconv.Atoi(inputStr[start:v]) //number (n) that comes before dateIdfr; e.g. if input = '3m', dateIdfr = 'm' & n = '3'
-			if e != nil {
-				return nil, literalDateStr, &UnknownDateInputError{}
-			}
-			mp[dateIdfr] = intPrefix
-		}
-
-		start = v + 1
-	}
-	return mp, literalDateStr, e
-}
-
-func getEmptyDateMap() map[string]int {
-	mp := make(map[string]int)
-	y, m, d := "y", "m", "d"
-	mp[y] = 0
-	mp[m] = 0
-	mp[d] = 0
-
-	return mp
-}
-
-func convertToDateString(input string, nowMo time.Time) (string, error) {
-
-	mp, literal, err := getDateMap(input)
-	if err != nil {
-		return "", err
-	}
-	if literal {
-		return input, nil
-	}
-
-	var yInt, mInt, dInt int
-	val, ok := mp["y"]
-	if ok {
-		yInt = val
-	}
-	val, ok = mp["m"]
-	if ok {
-		mInt = val
-	}
-	val, ok = mp["d"]
-	if ok {
-		dInt = val
-	}
-
-	newNow := nowMo.AddDate(yInt, mInt, dInt)
-	yy, mm, dd := newNow.Date()
-
-	zPrefM, zPrefD := "", ""
-	if int(mm) < 10 {
-		zPrefM = "0"
-	}
-	if dd < 10 {
-		zPrefD = "0"
-	}
-
-	return fmt.Sprintf("%v-%v%v-%v%v", yy, zPrefM, int(mm), zPrefD, dd), nil
-}
-
 // Appends standalone flags to end of input. Since they're
 // standalone, placement isn't significant but sequential order is maintained.
 func (fp *FlagParser) reassemble(input []string, standalones map[int]string) []string {
@@ -676,21 +845,3 @@ func StringFromSlice(sl []string) string {
 	}
 	return strings.Trim(bodyStr, " ")
 }
-
-func StringFromDate(d time.Time) string {
-	m := int(d.Month())
-	var mStr, dStr string
-	if m < 10 {
-		mStr = "0" + fmt.Sprint(m)
-	} else {
-		mStr = fmt.Sprint(m)
-	}
-	if d.Day() < 10 {
-		dStr = "0" + fmt.Sprint(d.Day())
-	} else {
-		dStr = fmt.Sprint(d.Day())
-	}
-	final := fmt.Sprintf("%v-%v-%v", d.Year(), mStr, dStr)
-
-	return final
-}
@@ -2,6 +2,7 @@ package flagParser
 
 import (
 	"os"
+	"reflect"
 	"strconv"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ type parsing_test_case struct {
 	systemFlags    func() []FlagInfo
 	err            error
 	dateTimeFormat string
+	configure      func(*FlagParser)
 }
 
 func _getNoSpaceBodyTestCases() []parsing_test_case {
@@ -507,12 +509,15 @@ func TestParseGoDooEditing(t *testing.T) {
 func _runParseTest(t *testing.T, tc parsing_test_case) {
 
 	fp := NewFlagParser(tc.systemFlags(), tc.args, WithNowAs(returnNowString(), "2006-01-02"))
+	if tc.configure != nil {
+		tc.configure(fp)
+	}
 	got, err := fp.ParseUserInput()
 
-	if err != nil && err == tc.err {
+	if err != nil && _errorsMatch(err, tc.err) {
 		t.Logf(">>>>PASSED: operation threw correct error. \nExp\t'%v', \nGot\t'%v'", tc.err, err)
 		return
-	} else if err != nil && err != tc.err {
+	} else if err != nil && !_errorsMatch(err, tc.err) {
 		t.Errorf(">>>>FAILED: operation threw incorrect error. \nExp\t'%v', \nGot\t'%v'", tc.err, err)
 	}
 
@@ -528,6 +533,11 @@ func _runParseTest(t *testing.T, tc parsing_test_case) {
 	}
 }
 
+// Errors carrying details can't be compared with ==, so compare types
+func _errorsMatch(got, exp error) bool {
+	return reflect.TypeOf(got) == reflect.TypeOf(exp)
+}
+
 func _slicesAreTheSame(s1 []string, s2 []string) bool {
 	for i, s := range s1 {
 		if s != s2[i] {
@@ -637,7 +647,7 @@ func _runDateParseTest(t *testing.T, tc parsing_test_case) {
 	got, err := fp.ParseUserInput()
 
 	if err != nil {
-		if err == tc.err {
+		if _errorsMatch(err, tc.err) {
 			t.Logf(">>>>PASSED: operation threw error. \nExp\t'%v', \nGot\t'%v'", tc.err, err)
 			return
 		} else {
@@ -675,3 +685,136 @@ func TestBadInput(t *testing.T) {
 		})
 	}
 }
+
+type spec_test_case struct {
+	flags  []FlagInfo
+	name   string
+	issues int
+}
+
+func _getFlagSpecTestCases() []spec_test_case {
+	return []spec_test_case{{
+		flags:  []FlagInfo{{FlagName: "-b", FlagType: Str, MaxLen: 20}, {FlagName: "-a", FlagType: Boolean, Standalone: true}},
+		name:   "valid spec",
+		issues: 0,
+	}, {
+		flags:  []FlagInfo{},
+		name:   "empty spec",
+		issues: 1,
+	}, {
+		flags:  []FlagInfo{{FlagName: "-b", FlagType: Str, MaxLen: 20}, {FlagName: "-b", FlagType: Integer, MaxLen: 4}},
+		name:   "duplicate flag names",
+		issues: 1,
+	}, {
+		flags:  []FlagInfo{{FlagName: "-b", FlagType: Str, MaxLen: 20}, {FlagName: "-1", FlagType: Integer, MaxLen: 4}},
+		name:   "flag name clashes with negative numbers",
+		issues: 1,
+	}, {
+		flags:  []FlagInfo{{FlagName: "-b", FlagType: Str, MaxLen: 20}, {FlagName: "-d", FlagType: DateTime, MaxLen: 20, Standalone: true}},
+		name:   "standalone date flag",
+		issues: 1,
+	}, {
+		flags:  []FlagInfo{{FlagName: "-b", FlagType: Str}},
+		name:   "string flag without max length",
+		issues: 1,
+	}, {
+		flags:  []FlagInfo{{FlagName: "-a", FlagType: Boolean, Standalone: true}, {FlagName: "b", FlagType: Str, MaxLen: 20, AllowDateRange: true}},
+		name:   "standalone implicit flag, missing dash and range on string flag",
+		issues: 3,
+	}, {
+		flags:  []FlagInfo{{FlagName: "-d", FlagType: DateTime, MaxLen: 20, AllowDateRange: true, RangeSeparator: "-"}},
+		name:   "range separator clashes with negative dates",
+		issues: 1,
+	}, {
+		flags:  []FlagInfo{{FlagName: "-d", FlagType: DateTime, MaxLen: 20, AllowDateRange: true, RangeSeparator: " "}},
+		name:   "blank range separator",
+		issues: 1,
+	}, {
+		flags:  []FlagInfo{{FlagName: "-d", FlagType: DateTime, MaxLen: 20, AllowDateRange: true, MaxRangeSpan: "a year"}},
+		name:   "unknown max range span",
+		issues: 1,
+	}}
+}
+
+func TestFlagSpecValidation(t *testing.T) {
+	tcs := _getFlagSpecTestCases()
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewCheckedFlagParser(tc.flags, []string{"input"}, WithNowAs(returnNowString(), "2006-01-02"))
+
+			if tc.issues == 0 {
+				if err != nil {
+					t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+				}
+				return
+			}
+
+			specErr, ok := err.(*InvalidFlagSpecError)
+			if !ok {
+				t.Errorf(">>>>FAILED: expected spec error. \nGot\t'%v'", err)
+				return
+			}
+			if len(specErr.Issues) != tc.issues {
+				t.Errorf(">>>>FAILED: wrong issue count. \nExp\t'%v' \nGot\t'%v'", tc.issues, specErr)
+			}
+		})
+	}
+}
+
+type fixed_clock struct {
+	now time.Time
+}
+
+func (f fixed_clock) Now() time.Time {
+	return f.now
+}
+
+func TestNowMomentOptions(t *testing.T) {
+	os.Setenv("MAX_LENGTH", "2000")
+	os.Setenv("MAX_INT_DIGITS", "4")
+	os.Setenv("MAX_TAG_LENGTH", "10")
+
+	args := []string{"-d", "2d"}
+	now := time.Date(2022, 03, 14, 0, 0, 0, 0, time.UTC)
+
+	t.Run("bad now string from checked constructor", func(t *testing.T) {
+		fp, err := NewCheckedFlagParser(_getCanonicalFlagsForGodoGettingTests(), args, WithNowAs("14/03/2022", "2006-01-02"))
+		if _, ok := err.(*NowMomentParseError); !ok || fp != nil {
+			t.Errorf(">>>>FAILED: expected now parse error & no parser. \nGot\t'%v' '%v'", err, fp)
+		}
+		fp, err = NewCheckedParser(_getCanonicalFlagsForGodoGettingTests(), args, "14/03/2022", "2006-01-02")
+		if _, ok := err.(*NowMomentParseError); !ok || fp != nil {
+			t.Errorf(">>>>FAILED: expected now parse error & no parser. \nGot\t'%v' '%v'", err, fp)
+		}
+	})
+
+	t.Run("bad now string surfaces when parsing dates", func(t *testing.T) {
+		fp := NewFlagParser(_getCanonicalFlagsForGodoGettingTests(), args, WithNowAs("14/03/2022", "2006-01-02"))
+		_, err := fp.ParseUserInput()
+		if _, ok := err.(*NowMomentParseError); !ok {
+			t.Errorf(">>>>FAILED: expected now parse error. \nGot\t'%v'", err)
+		}
+	})
+
+	nowFuncs := map[string]NowMomentFunc{
+		"now moment": WithNowMoment(now),
+		"clock":      WithClock(fixed_clock{now: now}),
+	}
+	for name, nf := range nowFuncs {
+		t.Run(name, func(t *testing.T) {
+			fp, err := NewCheckedFlagParser(_getCanonicalFlagsForGodoGettingTests(), args, nf)
+			if err != nil {
+				t.Errorf(">>>>FAILED: unexpected error. \nGot\t'%v'", err)
+				return
+			}
+			if fp.DateTimeLayout != "2006-01-02" {
+				t.Errorf(">>>>FAILED: default layout. \nGot\t'%v'", fp.DateTimeLayout)
+			}
+			got, err := fp.ParseUserInput()
+			exp := []string{"-d", "2022-03-16"}
+			if err != nil || len(got) != len(exp) || !_slicesAreTheSame(exp, got) {
+				t.Errorf(">>>>FAILED: \nExp\t'%v' \nGot\t'%v' '%v'", exp, got, err)
+			}
+		})
+	}
+}